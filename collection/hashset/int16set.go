@@ -1,5 +1,12 @@
 package hashset
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
 type Int16Set struct {
 	m map[int16]struct{}
 }
@@ -56,3 +63,144 @@ func (s *Int16Set) GetSlice() []int16 {
 func (s *Int16Set) Len() int {
 	return len(s.m)
 }
+
+// Clone 返回集合的副本
+func (s *Int16Set) Clone() *Int16Set {
+	c := &Int16Set{m: make(map[int16]struct{}, len(s.m))}
+	for k := range s.m {
+		c.m[k] = exists
+	}
+	return c
+}
+
+// Union 返回两个集合的并集
+func (s *Int16Set) Union(another *Int16Set) *Int16Set {
+	c := s.Clone()
+	c.Merge(another)
+	return c
+}
+
+// Intersect 返回两个集合的交集
+func (s *Int16Set) Intersect(another *Int16Set) *Int16Set {
+	small, large := s, another
+	if len(small.m) > len(large.m) {
+		small, large = large, small
+	}
+
+	c := NewInt16Set()
+	for k := range small.m {
+		if _, ok := large.m[k]; ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// Difference 返回在 s 中但不在 another 中的元素
+func (s *Int16Set) Difference(another *Int16Set) *Int16Set {
+	c := NewInt16Set()
+	for k := range s.m {
+		if _, ok := another.m[k]; !ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// SymmetricDifference 返回只在其中一个集合中的元素
+func (s *Int16Set) SymmetricDifference(another *Int16Set) *Int16Set {
+	c := s.Difference(another)
+	for k := range another.m {
+		if _, ok := s.m[k]; !ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// IsSubset 判断 s 是否为 another 的子集
+func (s *Int16Set) IsSubset(another *Int16Set) bool {
+	if len(s.m) > len(another.m) {
+		return false
+	}
+	for k := range s.m {
+		if _, ok := another.m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset 判断 s 是否为 another 的超集
+func (s *Int16Set) IsSuperset(another *Int16Set) bool {
+	return another.IsSubset(s)
+}
+
+// Pop 删除并返回任意一个元素，集合为空时返回 false
+func (s *Int16Set) Pop() (int16, bool) {
+	for k := range s.m {
+		delete(s.m, k)
+		return k, true
+	}
+	var zero int16
+	return zero, false
+}
+
+// Filter 返回 f 为 true 的元素组成的集合
+func (s *Int16Set) Filter(f func(value int16) bool) *Int16Set {
+	c := NewInt16Set()
+	for k := range s.m {
+		if f(k) {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// SortedSlice 返回升序排列的元素
+func (s *Int16Set) SortedSlice() []int16 {
+	slice := s.GetSlice()
+	sort.Slice(slice, func(i, j int) bool {
+		return slice[i] < slice[j]
+	})
+	return slice
+}
+
+// MarshalJSON 编码为升序排列的 JSON 数组
+func (s *Int16Set) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.SortedSlice())
+}
+
+// UnmarshalJSON 由 JSON 数组解码，替换原有元素
+func (s *Int16Set) UnmarshalJSON(data []byte) error {
+	var values []int16
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*s = *NewInt16Set(values...)
+	return nil
+}
+
+// Value 存储为 JSON 数组
+func (s *Int16Set) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := s.MarshalJSON()
+	return string(b), err
+}
+
+// Scan 由 JSON 数组读取，NULL 为空集合
+func (s *Int16Set) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		*s = *NewInt16Set()
+		return nil
+	case []byte:
+		return s.UnmarshalJSON(value)
+	case string:
+		return s.UnmarshalJSON([]byte(value))
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to Int16Set", v, v)
+	}
+}
@@ -1,5 +1,12 @@
 package hashset
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
 type Int32Set struct {
 	m map[int32]struct{}
 }
@@ -56,3 +63,144 @@ func (s *Int32Set) GetSlice() []int32 {
 func (s *Int32Set) Len() int {
 	return len(s.m)
 }
+
+// Clone 返回集合的副本
+func (s *Int32Set) Clone() *Int32Set {
+	c := &Int32Set{m: make(map[int32]struct{}, len(s.m))}
+	for k := range s.m {
+		c.m[k] = exists
+	}
+	return c
+}
+
+// Union 返回两个集合的并集
+func (s *Int32Set) Union(another *Int32Set) *Int32Set {
+	c := s.Clone()
+	c.Merge(another)
+	return c
+}
+
+// Intersect 返回两个集合的交集
+func (s *Int32Set) Intersect(another *Int32Set) *Int32Set {
+	small, large := s, another
+	if len(small.m) > len(large.m) {
+		small, large = large, small
+	}
+
+	c := NewInt32Set()
+	for k := range small.m {
+		if _, ok := large.m[k]; ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// Difference 返回在 s 中但不在 another 中的元素
+func (s *Int32Set) Difference(another *Int32Set) *Int32Set {
+	c := NewInt32Set()
+	for k := range s.m {
+		if _, ok := another.m[k]; !ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// SymmetricDifference 返回只在其中一个集合中的元素
+func (s *Int32Set) SymmetricDifference(another *Int32Set) *Int32Set {
+	c := s.Difference(another)
+	for k := range another.m {
+		if _, ok := s.m[k]; !ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// IsSubset 判断 s 是否为 another 的子集
+func (s *Int32Set) IsSubset(another *Int32Set) bool {
+	if len(s.m) > len(another.m) {
+		return false
+	}
+	for k := range s.m {
+		if _, ok := another.m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset 判断 s 是否为 another 的超集
+func (s *Int32Set) IsSuperset(another *Int32Set) bool {
+	return another.IsSubset(s)
+}
+
+// Pop 删除并返回任意一个元素，集合为空时返回 false
+func (s *Int32Set) Pop() (int32, bool) {
+	for k := range s.m {
+		delete(s.m, k)
+		return k, true
+	}
+	var zero int32
+	return zero, false
+}
+
+// Filter 返回 f 为 true 的元素组成的集合
+func (s *Int32Set) Filter(f func(value int32) bool) *Int32Set {
+	c := NewInt32Set()
+	for k := range s.m {
+		if f(k) {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// SortedSlice 返回升序排列的元素
+func (s *Int32Set) SortedSlice() []int32 {
+	slice := s.GetSlice()
+	sort.Slice(slice, func(i, j int) bool {
+		return slice[i] < slice[j]
+	})
+	return slice
+}
+
+// MarshalJSON 编码为升序排列的 JSON 数组
+func (s *Int32Set) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.SortedSlice())
+}
+
+// UnmarshalJSON 由 JSON 数组解码，替换原有元素
+func (s *Int32Set) UnmarshalJSON(data []byte) error {
+	var values []int32
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*s = *NewInt32Set(values...)
+	return nil
+}
+
+// Value 存储为 JSON 数组
+func (s *Int32Set) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := s.MarshalJSON()
+	return string(b), err
+}
+
+// Scan 由 JSON 数组读取，NULL 为空集合
+func (s *Int32Set) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		*s = *NewInt32Set()
+		return nil
+	case []byte:
+		return s.UnmarshalJSON(value)
+	case string:
+		return s.UnmarshalJSON([]byte(value))
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to Int32Set", v, v)
+	}
+}
@@ -1,6 +1,13 @@
 package hashset
 
-import "sync"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"unsafe"
+)
 
 type SafeFloat32Set struct {
 	sync.RWMutex
@@ -48,24 +55,21 @@ func (s *SafeFloat32Set) Range(f func(value float32) bool) {
 	s.RUnlock()
 }
 
+// Merge 合并 another 的元素
 func (s *SafeFloat32Set) Merge(another *SafeFloat32Set) {
-	s.Lock()
-	another.Range(func(str float32) bool {
-		s.Add(str)
-		return true
-	})
-	s.Unlock()
+	unlock := s.lockPair(another, true)
+	for k := range another.m {
+		s.m[k] = exists
+	}
+	unlock()
 }
 
 func (s *SafeFloat32Set) GetSlice() []float32 {
 	s.RLock()
-
 	slice := make([]float32, 0, len(s.m))
-	s.Range(func(value float32) bool {
-		slice = append(slice, value)
-		return true
-	})
-
+	for k := range s.m {
+		slice = append(slice, k)
+	}
 	s.RUnlock()
 	return slice
 }
@@ -76,3 +80,198 @@ func (s *SafeFloat32Set) Len() int {
 	s.RUnlock()
 	return l
 }
+
+// lockPair 对 s 加写锁（write 为 true 时）或读锁，对 another 加读锁。
+// 两个集合按地址顺序加锁，避免 a.Merge(b) 与 b.Merge(a) 等并发调用死锁。
+func (s *SafeFloat32Set) lockPair(another *SafeFloat32Set, write bool) (unlock func()) {
+	lock, unlockS := s.RLock, s.RUnlock
+	if write {
+		lock, unlockS = s.Lock, s.Unlock
+	}
+	if s == another {
+		lock()
+		return unlockS
+	}
+
+	if uintptr(unsafe.Pointer(s)) < uintptr(unsafe.Pointer(another)) {
+		lock()
+		another.RLock()
+	} else {
+		another.RLock()
+		lock()
+	}
+	return func() {
+		another.RUnlock()
+		unlockS()
+	}
+}
+
+func (s *SafeFloat32Set) clone() *SafeFloat32Set {
+	c := &SafeFloat32Set{m: make(map[float32]struct{}, len(s.m))}
+	for k := range s.m {
+		c.m[k] = exists
+	}
+	return c
+}
+
+// Clone 返回集合的副本
+func (s *SafeFloat32Set) Clone() *SafeFloat32Set {
+	s.RLock()
+	defer s.RUnlock()
+	return s.clone()
+}
+
+// Union 返回两个集合的并集
+func (s *SafeFloat32Set) Union(another *SafeFloat32Set) *SafeFloat32Set {
+	defer s.lockPair(another, false)()
+	c := s.clone()
+	for k := range another.m {
+		c.m[k] = exists
+	}
+	return c
+}
+
+// Intersect 返回两个集合的交集
+func (s *SafeFloat32Set) Intersect(another *SafeFloat32Set) *SafeFloat32Set {
+	defer s.lockPair(another, false)()
+	small, large := s, another
+	if len(small.m) > len(large.m) {
+		small, large = large, small
+	}
+
+	c := NewSafeFloat32Set()
+	for k := range small.m {
+		if _, ok := large.m[k]; ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// Difference 返回在 s 中但不在 another 中的元素
+func (s *SafeFloat32Set) Difference(another *SafeFloat32Set) *SafeFloat32Set {
+	defer s.lockPair(another, false)()
+	return s.difference(another)
+}
+
+func (s *SafeFloat32Set) difference(another *SafeFloat32Set) *SafeFloat32Set {
+	c := NewSafeFloat32Set()
+	for k := range s.m {
+		if _, ok := another.m[k]; !ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// SymmetricDifference 返回只在其中一个集合中的元素
+func (s *SafeFloat32Set) SymmetricDifference(another *SafeFloat32Set) *SafeFloat32Set {
+	defer s.lockPair(another, false)()
+	c := s.difference(another)
+	for k := range another.m {
+		if _, ok := s.m[k]; !ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// IsSubset 判断 s 是否为 another 的子集
+func (s *SafeFloat32Set) IsSubset(another *SafeFloat32Set) bool {
+	defer s.lockPair(another, false)()
+	if len(s.m) > len(another.m) {
+		return false
+	}
+	for k := range s.m {
+		if _, ok := another.m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset 判断 s 是否为 another 的超集
+func (s *SafeFloat32Set) IsSuperset(another *SafeFloat32Set) bool {
+	return another.IsSubset(s)
+}
+
+// Pop 删除并返回任意一个元素，集合为空时返回 false
+func (s *SafeFloat32Set) Pop() (float32, bool) {
+	s.Lock()
+	defer s.Unlock()
+	for k := range s.m {
+		delete(s.m, k)
+		return k, true
+	}
+	var zero float32
+	return zero, false
+}
+
+// Filter 返回 f 为 true 的元素组成的集合，f 在读锁内调用，不能修改 s
+func (s *SafeFloat32Set) Filter(f func(value float32) bool) *SafeFloat32Set {
+	s.RLock()
+	defer s.RUnlock()
+	c := NewSafeFloat32Set()
+	for k := range s.m {
+		if f(k) {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// SortedSlice 返回升序排列的元素
+func (s *SafeFloat32Set) SortedSlice() []float32 {
+	slice := s.GetSlice()
+	sort.Slice(slice, func(i, j int) bool {
+		return slice[i] < slice[j]
+	})
+	return slice
+}
+
+// MarshalJSON 编码为升序排列的 JSON 数组
+func (s *SafeFloat32Set) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.SortedSlice())
+}
+
+// UnmarshalJSON 由 JSON 数组解码，替换原有元素
+func (s *SafeFloat32Set) UnmarshalJSON(data []byte) error {
+	var values []float32
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	m := make(map[float32]struct{}, len(values))
+	for _, v := range values {
+		m[v] = exists
+	}
+	s.Lock()
+	s.m = m
+	s.Unlock()
+	return nil
+}
+
+// Value 存储为 JSON 数组
+func (s *SafeFloat32Set) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := s.MarshalJSON()
+	return string(b), err
+}
+
+// Scan 由 JSON 数组读取，NULL 为空集合
+func (s *SafeFloat32Set) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		s.Lock()
+		s.m = make(map[float32]struct{})
+		s.Unlock()
+		return nil
+	case []byte:
+		return s.UnmarshalJSON(value)
+	case string:
+		return s.UnmarshalJSON([]byte(value))
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to SafeFloat32Set", v, v)
+	}
+}
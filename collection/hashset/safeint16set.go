@@ -1,6 +1,13 @@
 package hashset
 
-import "sync"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"unsafe"
+)
 
 type SafeInt16Set struct {
 	sync.RWMutex
@@ -48,24 +55,21 @@ func (s *SafeInt16Set) Range(f func(value int16) bool) {
 	s.RUnlock()
 }
 
+// Merge 合并 another 的元素
 func (s *SafeInt16Set) Merge(another *SafeInt16Set) {
-	s.Lock()
-	another.Range(func(str int16) bool {
-		s.Add(str)
-		return true
-	})
-	s.Unlock()
+	unlock := s.lockPair(another, true)
+	for k := range another.m {
+		s.m[k] = exists
+	}
+	unlock()
 }
 
 func (s *SafeInt16Set) GetSlice() []int16 {
 	s.RLock()
-
 	slice := make([]int16, 0, len(s.m))
-	s.Range(func(value int16) bool {
-		slice = append(slice, value)
-		return true
-	})
-
+	for k := range s.m {
+		slice = append(slice, k)
+	}
 	s.RUnlock()
 	return slice
 }
@@ -76,3 +80,198 @@ func (s *SafeInt16Set) Len() int {
 	s.RUnlock()
 	return l
 }
+
+// lockPair 对 s 加写锁（write 为 true 时）或读锁，对 another 加读锁。
+// 两个集合按地址顺序加锁，避免 a.Merge(b) 与 b.Merge(a) 等并发调用死锁。
+func (s *SafeInt16Set) lockPair(another *SafeInt16Set, write bool) (unlock func()) {
+	lock, unlockS := s.RLock, s.RUnlock
+	if write {
+		lock, unlockS = s.Lock, s.Unlock
+	}
+	if s == another {
+		lock()
+		return unlockS
+	}
+
+	if uintptr(unsafe.Pointer(s)) < uintptr(unsafe.Pointer(another)) {
+		lock()
+		another.RLock()
+	} else {
+		another.RLock()
+		lock()
+	}
+	return func() {
+		another.RUnlock()
+		unlockS()
+	}
+}
+
+func (s *SafeInt16Set) clone() *SafeInt16Set {
+	c := &SafeInt16Set{m: make(map[int16]struct{}, len(s.m))}
+	for k := range s.m {
+		c.m[k] = exists
+	}
+	return c
+}
+
+// Clone 返回集合的副本
+func (s *SafeInt16Set) Clone() *SafeInt16Set {
+	s.RLock()
+	defer s.RUnlock()
+	return s.clone()
+}
+
+// Union 返回两个集合的并集
+func (s *SafeInt16Set) Union(another *SafeInt16Set) *SafeInt16Set {
+	defer s.lockPair(another, false)()
+	c := s.clone()
+	for k := range another.m {
+		c.m[k] = exists
+	}
+	return c
+}
+
+// Intersect 返回两个集合的交集
+func (s *SafeInt16Set) Intersect(another *SafeInt16Set) *SafeInt16Set {
+	defer s.lockPair(another, false)()
+	small, large := s, another
+	if len(small.m) > len(large.m) {
+		small, large = large, small
+	}
+
+	c := NewSafeInt16Set()
+	for k := range small.m {
+		if _, ok := large.m[k]; ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// Difference 返回在 s 中但不在 another 中的元素
+func (s *SafeInt16Set) Difference(another *SafeInt16Set) *SafeInt16Set {
+	defer s.lockPair(another, false)()
+	return s.difference(another)
+}
+
+func (s *SafeInt16Set) difference(another *SafeInt16Set) *SafeInt16Set {
+	c := NewSafeInt16Set()
+	for k := range s.m {
+		if _, ok := another.m[k]; !ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// SymmetricDifference 返回只在其中一个集合中的元素
+func (s *SafeInt16Set) SymmetricDifference(another *SafeInt16Set) *SafeInt16Set {
+	defer s.lockPair(another, false)()
+	c := s.difference(another)
+	for k := range another.m {
+		if _, ok := s.m[k]; !ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// IsSubset 判断 s 是否为 another 的子集
+func (s *SafeInt16Set) IsSubset(another *SafeInt16Set) bool {
+	defer s.lockPair(another, false)()
+	if len(s.m) > len(another.m) {
+		return false
+	}
+	for k := range s.m {
+		if _, ok := another.m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset 判断 s 是否为 another 的超集
+func (s *SafeInt16Set) IsSuperset(another *SafeInt16Set) bool {
+	return another.IsSubset(s)
+}
+
+// Pop 删除并返回任意一个元素，集合为空时返回 false
+func (s *SafeInt16Set) Pop() (int16, bool) {
+	s.Lock()
+	defer s.Unlock()
+	for k := range s.m {
+		delete(s.m, k)
+		return k, true
+	}
+	var zero int16
+	return zero, false
+}
+
+// Filter 返回 f 为 true 的元素组成的集合，f 在读锁内调用，不能修改 s
+func (s *SafeInt16Set) Filter(f func(value int16) bool) *SafeInt16Set {
+	s.RLock()
+	defer s.RUnlock()
+	c := NewSafeInt16Set()
+	for k := range s.m {
+		if f(k) {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// SortedSlice 返回升序排列的元素
+func (s *SafeInt16Set) SortedSlice() []int16 {
+	slice := s.GetSlice()
+	sort.Slice(slice, func(i, j int) bool {
+		return slice[i] < slice[j]
+	})
+	return slice
+}
+
+// MarshalJSON 编码为升序排列的 JSON 数组
+func (s *SafeInt16Set) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.SortedSlice())
+}
+
+// UnmarshalJSON 由 JSON 数组解码，替换原有元素
+func (s *SafeInt16Set) UnmarshalJSON(data []byte) error {
+	var values []int16
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	m := make(map[int16]struct{}, len(values))
+	for _, v := range values {
+		m[v] = exists
+	}
+	s.Lock()
+	s.m = m
+	s.Unlock()
+	return nil
+}
+
+// Value 存储为 JSON 数组
+func (s *SafeInt16Set) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := s.MarshalJSON()
+	return string(b), err
+}
+
+// Scan 由 JSON 数组读取，NULL 为空集合
+func (s *SafeInt16Set) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		s.Lock()
+		s.m = make(map[int16]struct{})
+		s.Unlock()
+		return nil
+	case []byte:
+		return s.UnmarshalJSON(value)
+	case string:
+		return s.UnmarshalJSON([]byte(value))
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to SafeInt16Set", v, v)
+	}
+}
@@ -0,0 +1,79 @@
+package hashset
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntSetAlgebra(t *testing.T) {
+	a, b := NewIntSet(1, 2, 3), NewIntSet(3, 4)
+
+	assert.Equal(t, []int{1, 2, 3, 4}, a.Union(b).SortedSlice())
+	assert.Equal(t, []int{3}, a.Intersect(b).SortedSlice())
+	assert.Equal(t, []int{1, 2}, a.Difference(b).SortedSlice())
+	assert.Equal(t, []int{1, 2, 4}, a.SymmetricDifference(b).SortedSlice())
+	assert.True(t, NewIntSet(1, 3).IsSubset(a))
+	assert.False(t, b.IsSubset(a))
+	assert.True(t, a.IsSuperset(NewIntSet()))
+	assert.Equal(t, []int{2}, a.Filter(func(v int) bool { return v%2 == 0 }).SortedSlice())
+
+	c := a.Clone()
+	v, ok := c.Pop()
+	assert.True(t, ok)
+	assert.True(t, a.Contains(v))
+	assert.False(t, c.Contains(v))
+	assert.Equal(t, 3, a.Len())
+
+	data, err := json.Marshal(a)
+	assert.Nil(t, err)
+	assert.Equal(t, "[1,2,3]", string(data))
+
+	var s struct {
+		Tags *StringSet `json:"tags"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"tags":["b","a","b"]}`), &s))
+	assert.Equal(t, []string{"a", "b"}, s.Tags.SortedSlice())
+
+	value, err := s.Tags.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, `["a","b"]`, value)
+	var scanned StringSet
+	assert.Nil(t, scanned.Scan([]byte(`["c"]`)))
+	assert.True(t, scanned.Contains("c"))
+	assert.Nil(t, scanned.Scan(nil))
+	assert.Equal(t, 0, scanned.Len())
+}
+
+func TestSafeSetAlgebra(t *testing.T) {
+	a, b := NewSafeStringSet("a", "b"), NewSafeStringSet("b", "c")
+
+	assert.Equal(t, []string{"a", "b", "c"}, a.Union(b).SortedSlice())
+	assert.Equal(t, []string{"b"}, a.Intersect(b).SortedSlice())
+	assert.Equal(t, []string{"a", "c"}, a.SymmetricDifference(b).SortedSlice())
+	assert.True(t, a.IsSubset(a))
+	assert.Equal(t, []string{"a", "b"}, a.Union(a).SortedSlice())
+
+	a.Merge(a)
+	assert.Equal(t, 2, a.Len())
+
+	// a.Merge(b) and b.Merge(a) run concurrently with writers must not deadlock
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(4)
+		go func() { defer wg.Done(); a.Merge(b) }()
+		go func() { defer wg.Done(); b.Merge(a) }()
+		go func() { defer wg.Done(); a.Add("x") }()
+		go func() { defer wg.Done(); _ = b.Difference(a) }()
+	}
+	wg.Wait()
+	assert.Equal(t, []string{"a", "b", "c", "x"}, a.SortedSlice())
+
+	data, err := json.Marshal(a)
+	assert.Nil(t, err)
+	var decoded SafeStringSet
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.True(t, decoded.IsSuperset(a) && a.IsSuperset(&decoded))
+}
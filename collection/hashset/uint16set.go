@@ -1,5 +1,12 @@
 package hashset
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
 type Uint16Set struct {
 	m map[uint16]struct{}
 }
@@ -56,3 +63,144 @@ func (s *Uint16Set) GetSlice() []uint16 {
 func (s *Uint16Set) Len() int {
 	return len(s.m)
 }
+
+// Clone 返回集合的副本
+func (s *Uint16Set) Clone() *Uint16Set {
+	c := &Uint16Set{m: make(map[uint16]struct{}, len(s.m))}
+	for k := range s.m {
+		c.m[k] = exists
+	}
+	return c
+}
+
+// Union 返回两个集合的并集
+func (s *Uint16Set) Union(another *Uint16Set) *Uint16Set {
+	c := s.Clone()
+	c.Merge(another)
+	return c
+}
+
+// Intersect 返回两个集合的交集
+func (s *Uint16Set) Intersect(another *Uint16Set) *Uint16Set {
+	small, large := s, another
+	if len(small.m) > len(large.m) {
+		small, large = large, small
+	}
+
+	c := NewUint16Set()
+	for k := range small.m {
+		if _, ok := large.m[k]; ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// Difference 返回在 s 中但不在 another 中的元素
+func (s *Uint16Set) Difference(another *Uint16Set) *Uint16Set {
+	c := NewUint16Set()
+	for k := range s.m {
+		if _, ok := another.m[k]; !ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// SymmetricDifference 返回只在其中一个集合中的元素
+func (s *Uint16Set) SymmetricDifference(another *Uint16Set) *Uint16Set {
+	c := s.Difference(another)
+	for k := range another.m {
+		if _, ok := s.m[k]; !ok {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// IsSubset 判断 s 是否为 another 的子集
+func (s *Uint16Set) IsSubset(another *Uint16Set) bool {
+	if len(s.m) > len(another.m) {
+		return false
+	}
+	for k := range s.m {
+		if _, ok := another.m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset 判断 s 是否为 another 的超集
+func (s *Uint16Set) IsSuperset(another *Uint16Set) bool {
+	return another.IsSubset(s)
+}
+
+// Pop 删除并返回任意一个元素，集合为空时返回 false
+func (s *Uint16Set) Pop() (uint16, bool) {
+	for k := range s.m {
+		delete(s.m, k)
+		return k, true
+	}
+	var zero uint16
+	return zero, false
+}
+
+// Filter 返回 f 为 true 的元素组成的集合
+func (s *Uint16Set) Filter(f func(value uint16) bool) *Uint16Set {
+	c := NewUint16Set()
+	for k := range s.m {
+		if f(k) {
+			c.m[k] = exists
+		}
+	}
+	return c
+}
+
+// SortedSlice 返回升序排列的元素
+func (s *Uint16Set) SortedSlice() []uint16 {
+	slice := s.GetSlice()
+	sort.Slice(slice, func(i, j int) bool {
+		return slice[i] < slice[j]
+	})
+	return slice
+}
+
+// MarshalJSON 编码为升序排列的 JSON 数组
+func (s *Uint16Set) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.SortedSlice())
+}
+
+// UnmarshalJSON 由 JSON 数组解码，替换原有元素
+func (s *Uint16Set) UnmarshalJSON(data []byte) error {
+	var values []uint16
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*s = *NewUint16Set(values...)
+	return nil
+}
+
+// Value 存储为 JSON 数组
+func (s *Uint16Set) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := s.MarshalJSON()
+	return string(b), err
+}
+
+// Scan 由 JSON 数组读取，NULL 为空集合
+func (s *Uint16Set) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		*s = *NewUint16Set()
+		return nil
+	case []byte:
+		return s.UnmarshalJSON(value)
+	case string:
+		return s.UnmarshalJSON([]byte(value))
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to Uint16Set", v, v)
+	}
+}
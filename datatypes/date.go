@@ -0,0 +1,296 @@
+package datatypes
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Date is a calendar date without time and location, stored as "2006-01-02".
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns the date, normalizing values out of range like time.Date does.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the date of t in the location of t.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// Today returns the date of now in time.Local.
+func Today() Date {
+	return DateOf(time.Now())
+}
+
+// ParseDate parses "2006-01-02".
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateFormat, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("datatypes: parsing date %q: %w", s, err)
+	}
+	return DateOf(t), nil
+}
+
+func (d Date) String() string {
+	return string(d.appendFormat(nil))
+}
+
+func (d Date) appendFormat(b []byte) []byte {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC).AppendFormat(b, dateFormat)
+}
+
+// In returns the start of the date in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero reports whether d is the zero Date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// IsValid reports whether d is a valid date.
+func (d Date) IsValid() bool {
+	return NewDate(d.Year, d.Month, d.Day) == d
+}
+
+// AddDays returns the date n days after d.
+func (d Date) AddDays(n int) Date {
+	return NewDate(d.Year, d.Month, d.Day+n)
+}
+
+// AddDate returns the date adding years, months and days like time.Time.AddDate.
+func (d Date) AddDate(years int, months int, days int) Date {
+	return NewDate(d.Year+years, d.Month+time.Month(months), d.Day+days)
+}
+
+// DaysSince returns the number of days from s to d.
+func (d Date) DaysSince(s Date) int {
+	return int(d.In(time.UTC).Sub(s.In(time.UTC)) / (24 * time.Hour))
+}
+
+func (d Date) Weekday() time.Weekday {
+	return d.In(time.UTC).Weekday()
+}
+
+func (d Date) Before(d1 Date) bool {
+	if d.Year != d1.Year {
+		return d.Year < d1.Year
+	}
+	if d.Month != d1.Month {
+		return d.Month < d1.Month
+	}
+	return d.Day < d1.Day
+}
+
+func (d Date) After(d1 Date) bool {
+	return d1.Before(d)
+}
+
+// MarshalJSON implements the json.Marshaler interface, the zero Date is encoded as null.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	b := append([]byte{'"'}, d.appendFormat(nil)...)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*d = Date{}
+		return nil
+	}
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("datatypes: parsing date %s: %w", data, err)
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d Date) MarshalText() ([]byte, error) {
+	if d.IsZero() {
+		return []byte{}, nil
+	}
+	return d.appendFormat(nil), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *Date) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*d = Date{}
+		return nil
+	}
+	date, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// Value implements the driver.Valuer interface, the zero Date is stored as NULL.
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.String(), nil
+}
+
+// Scan implements the sql.Scanner interface, accepting time.Time, string and []byte.
+func (d *Date) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = DateOf(value)
+	case []byte:
+		return d.scanText(string(value))
+	case string:
+		return d.scanText(value)
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to %T", v, v, d)
+	}
+	return nil
+}
+
+// scanText accepts DATETIME text as well, as returned by some drivers for DATE columns.
+func (d *Date) scanText(s string) error {
+	if len(s) > len(dateFormat) {
+		s = s[:len(dateFormat)]
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// Clock is a time of day without date and location, stored as "15:04:05".
+type Clock struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+const clockFormat = "15:04:05"
+
+// NewClock returns the time of day.
+func NewClock(hour, minute, second int) Clock {
+	return Clock{Hour: hour, Minute: minute, Second: second}
+}
+
+// ClockOf returns the time of day of t in the location of t.
+func ClockOf(t time.Time) Clock {
+	return Clock{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second(), Nanosecond: t.Nanosecond()}
+}
+
+// ParseClock parses "15:04:05", "15:04:05.999999999" or "15:04".
+func ParseClock(s string) (Clock, error) {
+	t, err := ParseTime(s, []string{"15:04:05.999999999", "15:04"}, time.UTC)
+	if err != nil {
+		return Clock{}, err
+	}
+	return ClockOf(t), nil
+}
+
+func (c Clock) String() string {
+	return string(c.appendFormat(nil))
+}
+
+func (c Clock) appendFormat(b []byte) []byte {
+	layout := clockFormat
+	if c.Nanosecond != 0 {
+		layout += ".999999999"
+	}
+	return c.On(Date{Year: 2000, Month: time.January, Day: 1}, time.UTC).AppendFormat(b, layout)
+}
+
+// On returns the time of c on the date d in loc.
+func (c Clock) On(d Date, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, c.Hour, c.Minute, c.Second, c.Nanosecond, loc)
+}
+
+// SinceMidnight returns the duration from midnight to c.
+func (c Clock) SinceMidnight() time.Duration {
+	return time.Duration(c.Hour)*time.Hour + time.Duration(c.Minute)*time.Minute +
+		time.Duration(c.Second)*time.Second + time.Duration(c.Nanosecond)
+}
+
+// IsValid reports whether c is a valid time of day.
+func (c Clock) IsValid() bool {
+	return c.Hour >= 0 && c.Hour < 24 && c.Minute >= 0 && c.Minute < 60 &&
+		c.Second >= 0 && c.Second < 60 && c.Nanosecond >= 0 && c.Nanosecond < 1e9
+}
+
+func (c Clock) Before(c1 Clock) bool {
+	return c.SinceMidnight() < c1.SinceMidnight()
+}
+
+func (c Clock) After(c1 Clock) bool {
+	return c.SinceMidnight() > c1.SinceMidnight()
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (c Clock) MarshalJSON() ([]byte, error) {
+	b := append([]byte{'"'}, c.appendFormat(nil)...)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *Clock) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*c = Clock{}
+		return nil
+	}
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("datatypes: parsing clock %s: %w", data, err)
+	}
+	return c.UnmarshalText([]byte(s))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (c Clock) MarshalText() ([]byte, error) {
+	return c.appendFormat(nil), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (c *Clock) UnmarshalText(text []byte) error {
+	clock, err := ParseClock(string(text))
+	if err != nil {
+		return err
+	}
+	*c = clock
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (c Clock) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+// Scan implements the sql.Scanner interface, accepting time.Time, string and []byte.
+func (c *Clock) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		*c = Clock{}
+	case time.Time:
+		*c = ClockOf(value)
+	case []byte:
+		return c.UnmarshalText(value)
+	case string:
+		return c.UnmarshalText([]byte(value))
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to %T", v, v, c)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package datatypes
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDateAndClock(t *testing.T) {
+	d := NewDate(2022, time.February, 29)
+	if d != (Date{2022, time.March, 1}) || !d.IsValid() || (Date{2022, time.February, 30}).IsValid() {
+		t.Errorf("unexpected date %v", d)
+	}
+	if d.AddDays(-1).String() != "2022-02-28" || d.DaysSince(NewDate(2022, 1, 1)) != 59 {
+		t.Errorf("unexpected date arithmetic")
+	}
+
+	var dates []Date
+	if err := json.Unmarshal([]byte(`["2022-01-02",null]`), &dates); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(dates)
+	if string(b) != `["2022-01-02",null]` {
+		t.Errorf("unexpected json %s", b)
+	}
+
+	var scanned Date
+	for _, v := range []interface{}{"2022-01-02", []byte("2022-01-02 00:00:00"), time.Date(2022, 1, 2, 23, 0, 0, 0, time.Local)} {
+		if err := scanned.Scan(v); err != nil || scanned != dates[0] {
+			t.Errorf("scan %v: got %v, %v", v, scanned, err)
+		}
+	}
+	if err := scanned.Scan("2022-13-01"); err == nil {
+		t.Error("expected parse error")
+	}
+
+	c, err := ParseClock("09:30")
+	if err != nil || c != NewClock(9, 30, 0) {
+		t.Errorf("unexpected clock %v, %v", c, err)
+	}
+	if err = c.Scan([]byte("23:59:59.5")); err != nil || c.String() != "23:59:59.5" {
+		t.Errorf("unexpected clock %v, %v", c, err)
+	}
+	if v, _ := NewClock(8, 0, 0).Value(); v != "08:00:00" {
+		t.Errorf("unexpected value %v", v)
+	}
+	if got := NewClock(8, 0, 0).On(d, time.UTC); !got.Equal(time.Date(2022, 3, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time %v", got)
+	}
+}
+
+func TestTimeRange(t *testing.T) {
+	loc := SHLocation
+	r := DateRange(NewDate(2022, 1, 30), NewDate(2022, 2, 2), loc)
+	if r.Duration() != 4*24*time.Hour {
+		t.Errorf("unexpected duration %v", r.Duration())
+	}
+	if !r.Contains(r.Start) || r.Contains(r.End) {
+		t.Error("range should be half-open")
+	}
+
+	other := NewTimeRange(time.Date(2022, 2, 3, 0, 0, 0, 0, loc), time.Date(2022, 2, 1, 12, 0, 0, 0, loc))
+	got, ok := r.Intersect(other)
+	if !ok || !got.Start.Equal(other.Start) || !got.End.Equal(r.End) || !r.ContainsRange(got) {
+		t.Errorf("unexpected intersection %v", got)
+	}
+	if r.Overlaps(TimeRange{Start: r.End, End: r.End.Add(time.Hour)}) {
+		t.Error("adjacent ranges should not overlap")
+	}
+
+	days := TimeRange{Start: r.Start.Add(12 * time.Hour), End: r.End}.SplitByDay()
+	if len(days) != 4 || days[0].Duration() != 12*time.Hour || days[1].Start.Day() != 31 {
+		t.Errorf("unexpected days %v", days)
+	}
+	months := r.SplitByMonth()
+	if len(months) != 2 || months[1].Start.Month() != time.February || months[1].Start.Day() != 1 {
+		t.Errorf("unexpected months %v", months)
+	}
+	if hours := r.Split(25 * time.Hour); len(hours) != 4 || hours[3].Duration() != 21*time.Hour {
+		t.Errorf("unexpected split %v", hours)
+	}
+
+	v, err := r.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scanned TimeRange
+	if err = scanned.Scan(v); err != nil || !scanned.Start.Equal(r.Start) || !scanned.End.Equal(r.End) {
+		t.Errorf("scan %v: got %v, %v", v, scanned, err)
+	}
+
+	var decoded []TimeRange
+	if err = json.Unmarshal([]byte(`[{"start":"2022-01-01T00:00:00Z","end":"2022-01-02T00:00:00Z"},"2022-01-01T00:00:00Z/2022-01-03T00:00:00Z"]`), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded[0].Duration() != 24*time.Hour || decoded[1].Duration() != 48*time.Hour {
+		t.Errorf("unexpected ranges %v", decoded)
+	}
+}
+
+func TestTimeRange_UnmarshalText(t *testing.T) {
+	start := time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	for text, want := range map[string]TimeRange{
+		`["2022-01-01 18:00:00+08","2022-01-01 19:00:00+08")`:       {start, end},
+		`["2022-01-01 15:30:00+05:30","2022-01-01 16:30:00+05:30")`: {start, end},
+		`("2022-01-01 10:00:00+00","2022-01-01 11:00:00+00"]`:       {start.Add(time.Microsecond), end.Add(time.Microsecond)},
+		`2022-01-01T10:00:00Z/2022-01-01T11:00:00Z`:                 {start, end},
+	} {
+		var r TimeRange
+		if err := r.UnmarshalText([]byte(text)); err != nil || !r.Start.Equal(want.Start) || !r.End.Equal(want.End) {
+			t.Errorf("parse %s: got %v, %v", text, r, err)
+		}
+	}
+
+	r := TimeRange{Start: start, End: end}
+	if err := r.UnmarshalText([]byte("empty")); err != nil || !r.IsEmpty() || !r.Start.IsZero() {
+		t.Errorf("parse empty: got %v, %v", r, err)
+	}
+	for _, text := range []string{`["2022-01-01 10:00:00+00",)`, `(,"2022-01-01 10:00:00+00")`, `[-infinity,infinity)`} {
+		if err := r.UnmarshalText([]byte(text)); !errors.Is(err, ErrUnboundedTimeRange) {
+			t.Errorf("parse %s: expected unbounded error, got %v", text, err)
+		}
+	}
+	if err := r.UnmarshalText([]byte(`["2022-01-01",`)); err == nil {
+		t.Error("expected error for unterminated range")
+	}
+}
@@ -0,0 +1,383 @@
+package datatypes
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrDivisionByZero is returned by Decimal.Div when the divisor is zero.
+var ErrDivisionByZero = errors.New("datatypes: decimal division by zero")
+
+// RoundingMode is the rounding mode of Decimal.
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to nearest, ties to even (banker's rounding).
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to nearest, ties away from zero.
+	RoundHalfUp
+	// RoundDown rounds toward zero (truncation).
+	RoundDown
+)
+
+// MaxDecimalScale bounds the scale of the parsed decimals in both directions,
+// so that untrusted input such as "1e2000000000" can not exhaust CPU or memory.
+const MaxDecimalScale = 1000
+
+// Decimal is an arbitrary precision decimal number, value * 10^-scale.
+// The zero value is 0. Decimal is immutable, all operations return a new Decimal.
+type Decimal struct {
+	value *big.Int
+	scale int32
+}
+
+var (
+	bigZero = big.NewInt(0)
+	bigTen  = big.NewInt(10)
+)
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// NewDecimal returns value * 10^-scale, such as NewDecimal(12345, 2) for 123.45.
+func NewDecimal(value int64, scale int32) Decimal {
+	return Decimal{value: big.NewInt(value), scale: scale}
+}
+
+// NewDecimalFromInt returns the Decimal of i.
+func NewDecimalFromInt(i int64) Decimal {
+	return NewDecimal(i, 0)
+}
+
+// NewDecimalFromFloat returns the Decimal of the shortest decimal representation of f.
+func NewDecimalFromFloat(f float64) (Decimal, error) {
+	return ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// ParseDecimal parses a decimal string such as "-1234.50", "1,234.50" or "1.5e3".
+// Commas are only accepted as the thousands separators of the integer part.
+// The digits after the decimal point minus the exponent must be within MaxDecimalScale.
+func ParseDecimal(s string) (Decimal, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(s[i+1:], 10, 32); err != nil {
+			return Decimal{}, fmt.Errorf("datatypes: parsing decimal %q: invalid exponent", orig)
+		}
+		s = s[:i]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if strings.Contains(intPart, ",") {
+		var ok bool
+		if intPart, ok = trimThousands(intPart); !ok {
+			return Decimal{}, fmt.Errorf("datatypes: parsing decimal %q: invalid thousands separator", orig)
+		}
+	}
+	digits := intPart + fracPart
+	sign := ""
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if digits == "" || strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return Decimal{}, fmt.Errorf("datatypes: parsing decimal %q: invalid syntax", orig)
+	}
+
+	scale := int64(len(fracPart)) - exp
+	if scale > MaxDecimalScale || scale < -MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("datatypes: parsing decimal %q: scale out of range [-%d, %d]", orig, MaxDecimalScale, MaxDecimalScale)
+	}
+
+	value, _ := new(big.Int).SetString(sign+digits, 10)
+	if scale < 0 {
+		value.Mul(value, pow10(int32(-scale)))
+		scale = 0
+	}
+	return Decimal{value: value, scale: int32(scale)}, nil
+}
+
+// trimThousands removes the commas of s such as "-1,234,567", each group after
+// the first one must have 3 digits.
+func trimThousands(s string) (string, bool) {
+	sign := ""
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		sign, s = s[:1], s[1:]
+	}
+	groups := strings.Split(s, ",")
+	if len(groups[0]) == 0 || len(groups[0]) > 3 {
+		return "", false
+	}
+	for _, g := range groups[1:] {
+		if len(g) != 3 {
+			return "", false
+		}
+	}
+	return sign + strings.Join(groups, ""), true
+}
+
+// MustParseDecimal is like ParseDecimal but panics on error.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) int() *big.Int {
+	if d.value == nil {
+		return bigZero
+	}
+	return d.value
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// rescale returns d.value at scale, scale must not be less than d.scale.
+func (d Decimal) rescale(scale int32) *big.Int {
+	if scale == d.scale {
+		return d.int()
+	}
+	return new(big.Int).Mul(d.int(), pow10(scale-d.scale))
+}
+
+func (d Decimal) Add(d1 Decimal) Decimal {
+	scale := max(d.scale, d1.scale)
+	return Decimal{value: new(big.Int).Add(d.rescale(scale), d1.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Sub(d1 Decimal) Decimal {
+	scale := max(d.scale, d1.scale)
+	return Decimal{value: new(big.Int).Sub(d.rescale(scale), d1.rescale(scale)), scale: scale}
+}
+
+// Mul returns d * d1 exactly, the scale is the sum of both scales.
+func (d Decimal) Mul(d1 Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.int(), d1.int()), scale: d.scale + d1.scale}
+}
+
+// Div returns d / d1 rounded to scale with mode.
+func (d Decimal) Div(d1 Decimal, scale int32, mode RoundingMode) (Decimal, error) {
+	if d1.int().Sign() == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+
+	// d / d1 = (d.value * 10^(scale - d.scale + d1.scale)) / d1.value * 10^-scale
+	num, den := new(big.Int).Set(d.int()), new(big.Int).Set(d1.int())
+	if exp := scale - d.scale + d1.scale; exp >= 0 {
+		num.Mul(num, pow10(exp))
+	} else {
+		den.Mul(den, pow10(-exp))
+	}
+	return Decimal{value: quoRound(num, den, mode), scale: scale}, nil
+}
+
+// quoRound returns num / den rounded with mode.
+func quoRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 || mode == RoundDown {
+		return q
+	}
+
+	// compare |2r| with |den|
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	c := twice.Cmp(new(big.Int).Abs(den))
+	if c > 0 || c == 0 && (mode == RoundHalfUp || q.Bit(0) == 1) {
+		if num.Sign()*den.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// Round returns d rounded to scale with mode, a larger scale pads zeros.
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale >= d.scale {
+		return Decimal{value: d.rescale(scale), scale: scale}
+	}
+	return Decimal{value: quoRound(d.int(), pow10(d.scale-scale), mode), scale: scale}
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Sign returns -1, 0 or 1.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp returns -1 if d < d1, 0 if d == d1 and 1 if d > d1, regardless of scales.
+func (d Decimal) Cmp(d1 Decimal) int {
+	scale := max(d.scale, d1.scale)
+	return d.rescale(scale).Cmp(d1.rescale(scale))
+}
+
+func (d Decimal) Equal(d1 Decimal) bool       { return d.Cmp(d1) == 0 }
+func (d Decimal) LessThan(d1 Decimal) bool    { return d.Cmp(d1) < 0 }
+func (d Decimal) GreaterThan(d1 Decimal) bool { return d.Cmp(d1) > 0 }
+
+// Rat returns d as *big.Rat.
+func (d Decimal) Rat() *big.Rat {
+	if d.scale >= 0 {
+		return new(big.Rat).SetFrac(d.int(), pow10(d.scale))
+	}
+	return new(big.Rat).SetInt(new(big.Int).Mul(d.int(), pow10(-d.scale)))
+}
+
+// Float64 returns the nearest float64 of d.
+func (d Decimal) Float64() float64 {
+	f, _ := d.Rat().Float64()
+	return f
+}
+
+// IntPart returns the integer part of d, truncated toward zero.
+func (d Decimal) IntPart() *big.Int {
+	return new(big.Int).Set(d.Round(0, RoundDown).int())
+}
+
+// String returns d with exactly Scale digits after the decimal point.
+func (d Decimal) String() string {
+	return d.format("")
+}
+
+// StringFixed returns d rounded half even to scale.
+func (d Decimal) StringFixed(scale int32) string {
+	return d.Round(scale, RoundHalfEven).String()
+}
+
+// FormatThousands returns d with the integer part grouped by sep, such as "1,234,567.89".
+func (d Decimal) FormatThousands(sep string) string {
+	return d.format(sep)
+}
+
+func (d Decimal) format(sep string) string {
+	if d.scale < 0 {
+		d = d.Round(0, RoundDown)
+	}
+
+	digits := new(big.Int).Abs(d.int()).String()
+	if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	intPart, fracPart := digits[:len(digits)-int(d.scale)], digits[len(digits)-int(d.scale):]
+
+	var b strings.Builder
+	if d.Sign() < 0 {
+		b.WriteByte('-')
+	}
+	for i := range intPart {
+		if sep != "" && i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteByte(intPart[i])
+	}
+	if fracPart != "" {
+		b.WriteByte('.')
+		b.WriteString(fracPart)
+	}
+	return b.String()
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoded as string since numbers
+// lose precision in most JSON decoders. Use DecimalNumber to encode as number.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting string and number.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(s))
+	}
+	return d.UnmarshalText(data)
+}
+
+// Number returns d as DecimalNumber, which is encoded as JSON number.
+func (d Decimal) Number() DecimalNumber {
+	return DecimalNumber{Decimal: d}
+}
+
+// DecimalNumber is Decimal encoded as JSON number instead of string, for the APIs
+// requiring numbers. It decodes both string and number like Decimal.
+type DecimalNumber struct {
+	Decimal
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoded as number.
+func (d DecimalNumber) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	dec, err := ParseDecimal(string(text))
+	if err != nil {
+		return err
+	}
+	*d = dec
+	return nil
+}
+
+// Value implements the driver.Valuer interface, stored as string for DECIMAL columns.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements the sql.Scanner interface, accepting []byte, string, int64 and float64.
+// NULL is scanned as 0, use Null[Decimal] for nullable columns.
+func (d *Decimal) Scan(v interface{}) error {
+	var err error
+	switch value := v.(type) {
+	case nil:
+		*d = Decimal{}
+	case []byte:
+		*d, err = ParseDecimal(string(value))
+	case string:
+		*d, err = ParseDecimal(value)
+	case int64:
+		*d = NewDecimalFromInt(value)
+	case float64:
+		*d, err = NewDecimalFromFloat(value)
+	default:
+		err = fmt.Errorf("can not scan value %v (%T) to %T", v, v, d)
+	}
+	return err
+}
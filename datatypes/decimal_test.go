@@ -0,0 +1,198 @@
+package datatypes
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecimalRound(t *testing.T) {
+	var cases = []struct {
+		in       string
+		scale    int32
+		mode     RoundingMode
+		expected string
+	}{
+		{"2.345", 2, RoundHalfEven, "2.34"},
+		{"2.355", 2, RoundHalfEven, "2.36"},
+		{"2.345", 2, RoundHalfUp, "2.35"},
+		{"-2.345", 2, RoundHalfUp, "-2.35"},
+		{"-2.345", 2, RoundHalfEven, "-2.34"},
+		{"2.349", 2, RoundDown, "2.34"},
+		{"-2.349", 2, RoundDown, "-2.34"},
+		{"2.5", 0, RoundHalfEven, "2"},
+		{"1.5e2", 2, RoundHalfEven, "150.00"},
+		{"0.001", 2, RoundHalfUp, "0.00"},
+	}
+	for _, c := range cases {
+		got := MustParseDecimal(c.in).Round(c.scale, c.mode).String()
+		if got != c.expected {
+			t.Errorf("round %s to %d with %d: expected %s, got %s", c.in, c.scale, c.mode, c.expected, got)
+		}
+	}
+
+	for _, s := range []string{"", "abc", "1.2.3", "1e", "-", "1,2,3", ",123", "1234,567", "1,234.5,6", "1,234e1,0"} {
+		if _, err := ParseDecimal(s); err == nil {
+			t.Errorf("parse %q: expected error", s)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a, b := MustParseDecimal("0.1"), MustParseDecimal("0.2")
+	if sum := a.Add(b); !sum.Equal(MustParseDecimal("0.3")) || sum.String() != "0.3" {
+		t.Errorf("unexpected sum %s", sum)
+	}
+	if diff := a.Sub(MustParseDecimal("1.25")); diff.String() != "-1.15" {
+		t.Errorf("unexpected difference %s", diff)
+	}
+	if prod := MustParseDecimal("1.5").Mul(MustParseDecimal("-0.25")); prod.String() != "-0.375" {
+		t.Errorf("unexpected product %s", prod)
+	}
+	q, err := NewDecimalFromInt(10).Div(NewDecimalFromInt(3), 4, RoundHalfUp)
+	if err != nil || q.String() != "3.3333" {
+		t.Errorf("unexpected quotient %s, %v", q, err)
+	}
+	q, _ = NewDecimalFromInt(-2).Div(NewDecimalFromInt(3), 2, RoundHalfUp)
+	if q.String() != "-0.67" {
+		t.Errorf("unexpected quotient %s", q)
+	}
+	if _, err = a.Div(Decimal{}, 2, RoundDown); !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("expected division by zero, got %v", err)
+	}
+	if !a.LessThan(b) || b.Cmp(MustParseDecimal("0.20")) != 0 || (Decimal{}).String() != "0" {
+		t.Error("unexpected comparison")
+	}
+	if s := MustParseDecimal("-1234567.891").FormatThousands(","); s != "-1,234,567.891" {
+		t.Errorf("unexpected format %s", s)
+	}
+	if s := MustParseDecimal("123.4").FormatThousands(","); s != "123.4" {
+		t.Errorf("unexpected format %s", s)
+	}
+	if d := MustParseDecimal("-1,234,567.891"); d.String() != "-1234567.891" {
+		t.Errorf("unexpected parse %s", d)
+	}
+}
+
+func TestDecimalCodec(t *testing.T) {
+	var d struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":"12.50","b":0.1}`), &d); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(d)
+	if string(b) != `{"a":"12.50","b":"0.1"}` {
+		t.Errorf("unexpected json %s", b)
+	}
+
+	var scanned Decimal
+	for _, v := range []interface{}{[]byte("12.50"), "12.5", float64(12.5)} {
+		if err := scanned.Scan(v); err != nil || !scanned.Equal(d.A) {
+			t.Errorf("scan %v: got %s, %v", v, scanned, err)
+		}
+	}
+	if v, _ := d.A.Value(); v != "12.50" {
+		t.Errorf("unexpected value %v", v)
+	}
+
+	for _, data := range []string{`"1.5`, `""1.5""`, `"1.5"x`, `1.5"`} {
+		if err := d.A.UnmarshalJSON([]byte(data)); err == nil {
+			t.Errorf("unmarshal %s: expected error", data)
+		}
+	}
+
+	n := struct {
+		A Decimal       `json:"a"`
+		B DecimalNumber `json:"b"`
+	}{MustParseDecimal("1.10"), MustParseDecimal("0.25").Number()}
+	if b, _ = json.Marshal(n); string(b) != `{"a":"1.10","b":0.25}` {
+		t.Errorf("unexpected json %s", b)
+	}
+	if err := json.Unmarshal([]byte(`{"b":"3.5"}`), &n); err != nil || n.B.String() != "3.5" {
+		t.Errorf("unexpected decimal number %s, %v", n.B, err)
+	}
+}
+
+func TestParseDecimalBounds(t *testing.T) {
+	for _, s := range []string{"1e1000", "1e-1000", "0." + strings.Repeat("1", 1000)} {
+		if d, err := ParseDecimal(s); err != nil {
+			t.Errorf("parse %q: %v", s, err)
+		} else if d.Scale() < -MaxDecimalScale || d.Scale() > MaxDecimalScale {
+			t.Errorf("parse %q: unexpected scale %d", s, d.Scale())
+		}
+	}
+
+	for _, s := range []string{"1e1001", "1e-1001", "1e20000000", "1e2000000000", "1e-2147483647", "1e99999999999", "0." + strings.Repeat("1", 1001)} {
+		if _, err := ParseDecimal(s); err == nil {
+			t.Errorf("parse %q: expected error", s)
+		}
+	}
+
+	var d Decimal
+	if err := json.Unmarshal([]byte(`"1e20000000"`), &d); err == nil {
+		t.Error("unmarshal: expected error")
+	}
+	if err := d.Scan("1e-2147483647"); err == nil {
+		t.Error("scan: expected error")
+	}
+}
+
+func TestMoney(t *testing.T) {
+	price, err := NewMoney(MustParseDecimal("1999.995"), "cny")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.String() != "CNY 2,000.00" {
+		t.Errorf("unexpected money %s", price)
+	}
+
+	yen, _ := NewMoney(MustParseDecimal("1000"), "JPY")
+	if _, err = price.Add(yen); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected currency mismatch, got %v", err)
+	}
+	if got := yen.Mul(MustParseDecimal("0.125"), RoundHalfEven); got.Amount.String() != "125" {
+		t.Errorf("unexpected amount %s", got.Amount)
+	}
+
+	var m Money
+	if err = json.Unmarshal([]byte(`{"amount":"1.5","currency":"usd1"}`), &m); err == nil {
+		t.Error("expected invalid currency")
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	type order struct {
+		Price Money  `json:"price"`
+		Tax   *Money `json:"tax"`
+	}
+
+	price, _ := NewMoney(MustParseDecimal("12.50"), "USD")
+	for _, o := range []order{{}, {Price: price, Tax: &price}} {
+		b, err := json.Marshal(o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got order
+		if err = json.Unmarshal(b, &got); err != nil {
+			t.Errorf("unmarshal %s: %v", b, err)
+			continue
+		}
+		if !got.Price.Amount.Equal(o.Price.Amount) || got.Price.Currency != o.Price.Currency {
+			t.Errorf("round trip %s: got %v", b, got.Price)
+		}
+		if (got.Tax == nil) != (o.Tax == nil) || got.Tax != nil && got.Tax.String() != o.Tax.String() {
+			t.Errorf("round trip %s: got tax %v", b, got.Tax)
+		}
+	}
+
+	var o order
+	if err := json.Unmarshal([]byte(`{"price":null,"tax":null}`), &o); err != nil || o.Price.Currency != "" || !o.Price.IsZero() || o.Tax != nil {
+		t.Errorf("unmarshal null: got %+v, %v", o, err)
+	}
+	if err := json.Unmarshal([]byte(`{"price":{"amount":"1","currency":""}}`), &o); err == nil {
+		t.Error("expected invalid currency for non-zero amount")
+	}
+}
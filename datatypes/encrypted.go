@@ -0,0 +1,273 @@
+package datatypes
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/joker-circus/gotools/security"
+)
+
+var (
+	// ErrNoKeyring is returned when Encrypted is used before SetKeyring.
+	ErrNoKeyring = errors.New("datatypes: encryption keyring is not set")
+	// ErrUnknownKey is returned by a Keyring for an unknown key id.
+	ErrUnknownKey = errors.New("datatypes: unknown encryption key")
+)
+
+// encryptedVersion prefixes the stored ciphertext: "v1:<key id>:<base64(nonce + ciphertext)>".
+// The prefix is also the additional data of AES-GCM, so the key id can not be tampered.
+const encryptedVersion = "v1"
+
+// RedactedText is the JSON of RedactedEncrypted and the String of Encrypted.
+const RedactedText = "******"
+
+// Keyring provides AES keys for Encrypted, keys are 16, 24 or 32 bytes.
+// Data is encrypted by the current key and decrypted by the key id stored with it,
+// so keys can be rotated by adding a new current key while keeping the old ones.
+type Keyring interface {
+	// CurrentKey returns the key used to encrypt.
+	CurrentKey() (id string, key []byte, err error)
+	// Key returns the key of id used to decrypt.
+	Key(id string) ([]byte, error)
+}
+
+var (
+	keyringMu sync.RWMutex
+	keyring   Keyring
+)
+
+// SetKeyring sets the Keyring used by Encrypted.
+func SetKeyring(k Keyring) {
+	keyringMu.Lock()
+	keyring = k
+	keyringMu.Unlock()
+}
+
+func getKeyring() (Keyring, error) {
+	keyringMu.RLock()
+	defer keyringMu.RUnlock()
+	if keyring == nil {
+		return nil, ErrNoKeyring
+	}
+	return keyring, nil
+}
+
+// StaticKeyring is an in-memory Keyring. The zero value is an empty keyring,
+// add keys by AddKey and set the current one by SetCurrent before use.
+type StaticKeyring struct {
+	mu      sync.RWMutex
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyring returns a Keyring encrypting with the key of current.
+// Usage:
+//
+//	k, err := datatypes.NewStaticKeyring("2024", map[string][]byte{"2023": oldKey, "2024": newKey})
+//	datatypes.SetKeyring(k)
+func NewStaticKeyring(current string, keys map[string][]byte) (*StaticKeyring, error) {
+	k := &StaticKeyring{keys: make(map[string][]byte, len(keys))}
+	for id, key := range keys {
+		if err := k.AddKey(id, key); err != nil {
+			return nil, err
+		}
+	}
+	if err := k.SetCurrent(current); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// AddKey adds or replaces the key of id.
+func (k *StaticKeyring) AddKey(id string, key []byte) error {
+	if id == "" || strings.Contains(id, ":") {
+		return fmt.Errorf("datatypes: invalid key id %q", id)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return fmt.Errorf("datatypes: invalid AES key size %d of key %q", len(key), id)
+	}
+
+	k.mu.Lock()
+	if k.keys == nil {
+		k.keys = make(map[string][]byte)
+	}
+	k.keys[id] = append([]byte(nil), key...)
+	k.mu.Unlock()
+	return nil
+}
+
+// SetCurrent sets the key used to encrypt, the key must be added.
+func (k *StaticKeyring) SetCurrent(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	k.current = id
+	return nil
+}
+
+// CurrentKey returns a copy of the current key.
+func (k *StaticKeyring) CurrentKey() (string, []byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if k.current == "" {
+		return "", nil, fmt.Errorf("%w: no current key", ErrUnknownKey)
+	}
+	return k.current, append([]byte(nil), k.keys[k.current]...), nil
+}
+
+// Key returns a copy of the key of id.
+func (k *StaticKeyring) Key(id string) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	return append([]byte(nil), key...), nil
+}
+
+// Encrypted is a column of T encrypted at rest. T is serialized as JSON and encrypted with
+// AES-GCM by the key of the Keyring set by SetKeyring, and is stored as
+// "v1:<key id>:<base64 ciphertext>". NULL and empty values are scanned as the zero value.
+// Use Null[Encrypted[T]] for nullable columns, and RedactedEncrypted[T] to keep the plain
+// value out of JSON.
+//
+// The ciphertext is bound to the key id only, not to the table, column or row, so a
+// ciphertext copied from another row or column of the same T decrypts successfully.
+// Store the owner, e.g. the user id, inside T and check it after Scan if that matters.
+type Encrypted[T any] struct {
+	V T
+}
+
+// NewEncrypted returns the Encrypted of v.
+func NewEncrypted[T any](v T) Encrypted[T] {
+	return Encrypted[T]{V: v}
+}
+
+// Get returns the plain value.
+func (e Encrypted[T]) Get() T {
+	return e.V
+}
+
+// Redact returns e as RedactedEncrypted, whose JSON is RedactedText.
+func (e Encrypted[T]) Redact() RedactedEncrypted[T] {
+	return RedactedEncrypted[T]{Encrypted: e}
+}
+
+// String is always RedactedText, to keep the plain value out of logs.
+func (e Encrypted[T]) String() string {
+	return RedactedText
+}
+
+// GoString is always RedactedText, to keep the plain value out of logs.
+func (e Encrypted[T]) GoString() string {
+	return RedactedText
+}
+
+// Value implements the driver.Valuer interface.
+func (e Encrypted[T]) Value() (driver.Value, error) {
+	k, err := getKeyring()
+	if err != nil {
+		return nil, err
+	}
+	id, key, err := k.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(e.V)
+	if err != nil {
+		return nil, err
+	}
+	prefix := encryptedVersion + ":" + id
+	encrypted, err := security.AesEncryptGCM(data, key, []byte(prefix))
+	if err != nil {
+		return nil, err
+	}
+	return prefix + ":" + security.Base64EncodeToString(encrypted), nil
+}
+
+// Scan implements the sql.Scanner interface, accepting string and []byte.
+func (e *Encrypted[T]) Scan(v interface{}) error {
+	var s string
+	switch value := v.(type) {
+	case nil:
+		*e = Encrypted[T]{}
+		return nil
+	case []byte:
+		s = string(value)
+	case string:
+		s = value
+	default:
+		return fmt.Errorf("can not scan value %T to %T", v, e)
+	}
+	if s == "" {
+		*e = Encrypted[T]{}
+		return nil
+	}
+
+	version, rest, _ := strings.Cut(s, ":")
+	id, text, ok := strings.Cut(rest, ":")
+	if version != encryptedVersion || !ok {
+		return fmt.Errorf("datatypes: unsupported encrypted data version %q", version)
+	}
+
+	k, err := getKeyring()
+	if err != nil {
+		return err
+	}
+	key, err := k.Key(id)
+	if err != nil {
+		return err
+	}
+	encrypted, err := security.Base64DecodeString(text)
+	if err != nil {
+		return fmt.Errorf("datatypes: decoding encrypted data: %w", err)
+	}
+	data, err := security.AesDecryptGCM(encrypted, key, []byte(version+":"+id))
+	if err != nil {
+		return fmt.Errorf("datatypes: decrypting data with key %q: %w", id, err)
+	}
+
+	var value T
+	if err = json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*e = Encrypted[T]{V: value}
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding the plain value.
+func (e Encrypted[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.V)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, decoding the plain value.
+func (e *Encrypted[T]) UnmarshalJSON(data []byte) error {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*e = Encrypted[T]{V: value}
+	return nil
+}
+
+// RedactedEncrypted is Encrypted whose JSON is RedactedText instead of the plain value,
+// to keep PII out of API responses and logs. It is stored the same as Encrypted, and
+// decodes the plain value from JSON.
+type RedactedEncrypted[T any] struct {
+	Encrypted[T]
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoding RedactedText.
+func (e RedactedEncrypted[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RedactedText)
+}
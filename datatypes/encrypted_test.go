@@ -0,0 +1,126 @@
+package datatypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEncrypted(t *testing.T) {
+	defer SetKeyring(nil)
+
+	if _, err := NewEncrypted("x").Value(); !errors.Is(err, ErrNoKeyring) {
+		t.Errorf("expected ErrNoKeyring, got %v", err)
+	}
+
+	k, err := NewStaticKeyring("k1", map[string][]byte{"k1": bytes.Repeat([]byte{1}, 16)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetKeyring(k)
+
+	type pii struct {
+		Phone string `json:"phone"`
+	}
+	v, err := NewEncrypted(pii{Phone: "13800000000"}).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := v.(string)
+	if !strings.HasPrefix(s, "v1:k1:") || strings.Contains(s, "13800000000") {
+		t.Errorf("unexpected ciphertext %s", s)
+	}
+
+	// rotate the key, data encrypted by the old key is still readable
+	if err = k.AddKey("k2", bytes.Repeat([]byte{2}, 32)); err != nil {
+		t.Fatal(err)
+	}
+	if err = k.SetCurrent("k2"); err != nil {
+		t.Fatal(err)
+	}
+	var e Encrypted[pii]
+	if err = e.Scan([]byte(s)); err != nil || e.Get().Phone != "13800000000" {
+		t.Errorf("unexpected scan %v, %v", e.V, err)
+	}
+	if v, _ = e.Value(); !strings.HasPrefix(v.(string), "v1:k2:") {
+		t.Errorf("expected current key k2, got %v", v)
+	}
+
+	var r RedactedEncrypted[pii]
+	if err = r.Scan(s); err != nil || r.Get().Phone != "13800000000" {
+		t.Errorf("unexpected redacted scan %v, %v", r.V, err)
+	}
+
+	tampered := strings.Replace(s, "v1:k1:", "v1:k2:", 1)
+	if err = e.Scan(tampered); err == nil {
+		t.Error("expected error for tampered key id")
+	}
+	if err = e.Scan("v1:k3:AAAA"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+	if err = e.Scan("v0:plain"); err == nil {
+		t.Error("expected unsupported version")
+	}
+
+	if _, err = NewStaticKeyring("k1", map[string][]byte{"k1": []byte("short")}); err == nil {
+		t.Error("expected invalid key size")
+	}
+}
+
+func TestStaticKeyring(t *testing.T) {
+	var k StaticKeyring
+	if _, _, err := k.CurrentKey(); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+
+	key := bytes.Repeat([]byte{1}, 16)
+	if err := k.AddKey("k1", key); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.SetCurrent("k1"); err != nil {
+		t.Fatal(err)
+	}
+	key[0] = 9
+
+	got, err := k.Key("k1")
+	if err != nil || got[0] != 1 {
+		t.Fatalf("unexpected key %v, %v", got, err)
+	}
+	got[0] = 9
+	_, current, _ := k.CurrentKey()
+	if current[0] != 1 {
+		t.Error("stored key is modified through Key")
+	}
+	current[0] = 9
+	if got, _ = k.Key("k1"); got[0] != 1 {
+		t.Error("stored key is modified through CurrentKey")
+	}
+}
+
+func TestEncryptedJSON(t *testing.T) {
+	e := NewEncrypted("secret")
+	if b, _ := json.Marshal(e); string(b) != `"secret"` {
+		t.Errorf("unexpected json %s", b)
+	}
+	if s := fmt.Sprintf("%v %#v", e, e); strings.Contains(s, "secret") {
+		t.Errorf("plain value leaked: %s", s)
+	}
+	if err := json.Unmarshal([]byte(`"plain"`), &e); err != nil || e.Get() != "plain" {
+		t.Errorf("unexpected unmarshal %v, %v", e.V, err)
+	}
+
+	// redaction is per field
+	user := struct {
+		Name  Encrypted[string]
+		Phone RedactedEncrypted[string]
+	}{NewEncrypted("joker"), NewEncrypted("123").Redact()}
+	if b, _ := json.Marshal(user); string(b) != `{"Name":"joker","Phone":"******"}` {
+		t.Errorf("unexpected json %s", b)
+	}
+	if err := json.Unmarshal([]byte(`{"Phone":"456"}`), &user); err != nil || user.Phone.Get() != "456" {
+		t.Errorf("unexpected unmarshal %v, %v", user.Phone.V, err)
+	}
+}
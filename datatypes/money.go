@@ -0,0 +1,124 @@
+package datatypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrCurrencyMismatch is returned by Money operations on different currencies.
+var ErrCurrencyMismatch = errors.New("datatypes: currency mismatch")
+
+// currencyDigits is the ISO 4217 minor unit of common currencies, the others default to 2.
+var currencyDigits = map[string]int32{
+	"BHD": 3, "CLP": 0, "IQD": 3, "ISK": 0, "JOD": 3, "JPY": 0, "KRW": 0,
+	"KWD": 3, "LYD": 3, "OMR": 3, "TND": 3, "UGX": 0, "VND": 0,
+}
+
+// CurrencyDigits returns the number of ISO 4217 minor unit digits of currency.
+func CurrencyDigits(currency string) int32 {
+	if digits, ok := currencyDigits[currency]; ok {
+		return digits
+	}
+	return 2
+}
+
+// Money is an amount of an ISO 4217 currency such as "CNY" or "USD".
+// Store it in two columns with `gorm:"embedded"`, Amount implements Scan and Value.
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// NewMoney returns the money, currency must be a three-letter ISO 4217 code.
+func NewMoney(amount Decimal, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	if len(currency) != 3 || strings.IndexFunc(currency, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		return Money{}, fmt.Errorf("datatypes: invalid currency code %q", currency)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+func (m Money) check(m1 Money) error {
+	if m.Currency != m1.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, m1.Currency)
+	}
+	return nil
+}
+
+func (m Money) Add(m1 Money) (Money, error) {
+	if err := m.check(m1); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Add(m1.Amount), Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(m1 Money) (Money, error) {
+	if err := m.check(m1); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: m.Amount.Sub(m1.Amount), Currency: m.Currency}, nil
+}
+
+// Mul returns m * d rounded to the minor unit of the currency with mode.
+func (m Money) Mul(d Decimal, mode RoundingMode) Money {
+	return Money{Amount: m.Amount.Mul(d).Round(CurrencyDigits(m.Currency), mode), Currency: m.Currency}
+}
+
+// Div returns m / d rounded to the minor unit of the currency with mode.
+func (m Money) Div(d Decimal, mode RoundingMode) (Money, error) {
+	amount, err := m.Amount.Div(d, CurrencyDigits(m.Currency), mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: m.Currency}, nil
+}
+
+// Round returns m rounded to the minor unit of the currency with mode.
+func (m Money) Round(mode RoundingMode) Money {
+	return Money{Amount: m.Amount.Round(CurrencyDigits(m.Currency), mode), Currency: m.Currency}
+}
+
+// Cmp compares the amounts of the same currency.
+func (m Money) Cmp(m1 Money) (int, error) {
+	if err := m.check(m1); err != nil {
+		return 0, err
+	}
+	return m.Amount.Cmp(m1.Amount), nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+// String returns such as "CNY 1,234.50", rounded half even to the minor unit.
+func (m Money) String() string {
+	return m.Currency + " " + m.Round(RoundHalfEven).Amount.FormatThousands(",")
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, validating the currency.
+// null and the JSON of the zero Money are decoded as the zero Money.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+
+	type money Money
+	var mm money
+	if err := json.Unmarshal(data, &mm); err != nil {
+		return err
+	}
+	if mm.Currency == "" && mm.Amount.IsZero() {
+		*m = Money{}
+		return nil
+	}
+	v, err := NewMoney(mm.Amount, mm.Currency)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
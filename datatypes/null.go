@@ -0,0 +1,388 @@
+package datatypes
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var _ SerializerInterface = &Null[string]{}
+
+// ErrNullValue is returned by MustGet when the value is null or absent.
+var ErrNullValue = errors.New("datatypes: value is null")
+
+// Null is a nullable value of T, replacing sql.NullString and friends as well as
+// pointer fields.
+//
+// Valid reports whether the value is not null. Present reports whether the value
+// was set at all, so that a JSON/YAML field which is absent can be told apart
+// from one which is explicitly null:
+//
+//	absent:  Present == false, Valid == false
+//	null:    Present == true,  Valid == false
+//	value:   Present == true,  Valid == true
+//
+// IsZero reports !Present, so with `yaml:",omitempty"` (or `json:",omitzero"` since Go 1.24)
+// an absent value is omitted while an explicit null is kept.
+type Null[T any] struct {
+	V       T
+	Valid   bool
+	Present bool
+}
+
+// NewNull returns a valid Null holding v.
+func NewNull[T any](v T) Null[T] {
+	return Null[T]{V: v, Valid: true, Present: true}
+}
+
+// NewNullPtr returns a Null holding *p, or an explicit null if p is nil.
+func NewNullPtr[T any](p *T) Null[T] {
+	if p == nil {
+		return ExplicitNull[T]()
+	}
+	return NewNull(*p)
+}
+
+// ExplicitNull returns a Null which is present but null.
+func ExplicitNull[T any]() Null[T] {
+	return Null[T]{Present: true}
+}
+
+// Get returns the value and whether it is valid.
+func (n Null[T]) Get() (T, bool) {
+	return n.V, n.Valid
+}
+
+// MustGet returns the value, it panics if the value is null.
+func (n Null[T]) MustGet() T {
+	if !n.Valid {
+		panic(ErrNullValue)
+	}
+	return n.V
+}
+
+// OrElse returns the value if valid, otherwise def.
+func (n Null[T]) OrElse(def T) T {
+	if !n.Valid {
+		return def
+	}
+	return n.V
+}
+
+// OrElseGet returns the value if valid, otherwise the result of f.
+func (n Null[T]) OrElseGet(f func() T) T {
+	if !n.Valid {
+		return f()
+	}
+	return n.V
+}
+
+// OrZero returns the value if valid, otherwise the zero value of T.
+func (n Null[T]) OrZero() T {
+	var zero T
+	return n.OrElse(zero)
+}
+
+// Ptr returns a pointer to a copy of the value, or nil if the value is null.
+func (n Null[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	v := n.V
+	return &v
+}
+
+// IsNull reports whether the value is null or absent.
+func (n Null[T]) IsNull() bool {
+	return !n.Valid
+}
+
+// IsAbsent reports whether the value was never set.
+func (n Null[T]) IsAbsent() bool {
+	return !n.Present
+}
+
+// IsZero reports whether the value is absent, used by omitempty/omitzero.
+func (n Null[T]) IsZero() bool {
+	return !n.Present
+}
+
+// Set sets the value to v.
+func (n *Null[T]) Set(v T) {
+	*n = NewNull(v)
+}
+
+// SetNull sets the value to an explicit null.
+func (n *Null[T]) SetNull() {
+	*n = ExplicitNull[T]()
+}
+
+// Unset makes the value absent.
+func (n *Null[T]) Unset() {
+	*n = Null[T]{}
+}
+
+func (n Null[T]) String() string {
+	if !n.Valid {
+		return "null"
+	}
+	return fmt.Sprint(n.V)
+}
+
+// Value implements the driver.Valuer interface.
+// Null values are written as NULL, types which can not be converted to a driver.Value
+// (structs, maps, slices) are written as JSON.
+func (n Null[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	if valuer, ok := any(n.V).(driver.Valuer); ok {
+		return valuer.Value()
+	}
+
+	v, err := driver.DefaultParameterConverter.ConvertValue(n.V)
+	if err == nil {
+		return v, nil
+	}
+	switch reflect.ValueOf(n.V).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		b, jsonErr := json.Marshal(n.V)
+		if jsonErr != nil {
+			return nil, jsonErr
+		}
+		return string(b), nil
+	}
+	return nil, err
+}
+
+// Scan implements the sql.Scanner interface.
+// Besides values assignable to T, it converts between the common driver types:
+// []byte/string to string, numbers, bool and time.Time, int64/float64 to any numeric kind,
+// and JSON text to structs, maps and slices.
+func (n *Null[T]) Scan(v interface{}) error {
+	if v == nil {
+		n.SetNull()
+		return nil
+	}
+
+	var value T
+	if scanner, ok := any(&value).(sql.Scanner); ok {
+		if err := scanner.Scan(v); err != nil {
+			return err
+		}
+	} else if err := convertAssign(reflect.ValueOf(&value).Elem(), v); err != nil {
+		return err
+	}
+	n.Set(value)
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (n Null[T]) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.V)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (n *Null[T]) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		n.SetNull()
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	n.Set(value)
+	return nil
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (n Null[T]) MarshalYAML() (interface{}, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.V, nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (n *Null[T]) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var p *T
+	if err := unmarshal(&p); err != nil {
+		return err
+	}
+	*n = NewNullPtr(p)
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// convertAssign assigns the driver value src to dest, converting between common types.
+func convertAssign(dest reflect.Value, src interface{}) error {
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dest.Type()) {
+		dest.Set(sv)
+		return nil
+	}
+
+	var text string
+	isText := false
+	switch s := src.(type) {
+	case []byte:
+		text, isText = string(s), true
+	case string:
+		text, isText = s, true
+	}
+
+	if isText && dest.Kind() == reflect.Struct && dest.Type().ConvertibleTo(timeType) {
+		t, err := parseTimeText(text)
+		if err != nil {
+			return fmt.Errorf("can not scan value %q to %s: %w", text, dest.Type(), err)
+		}
+		dest.Set(reflect.ValueOf(t).Convert(dest.Type()))
+		return nil
+	}
+
+	switch dest.Kind() {
+	case reflect.String:
+		switch s := src.(type) {
+		case []byte, string:
+			dest.SetString(text)
+		case int64:
+			dest.SetString(strconv.FormatInt(s, 10))
+		case float64:
+			dest.SetString(strconv.FormatFloat(s, 'g', -1, 64))
+		case bool:
+			dest.SetString(strconv.FormatBool(s))
+		case time.Time:
+			dest.SetString(s.Format(time.RFC3339Nano))
+		default:
+			return scanError(src, dest)
+		}
+		return nil
+	case reflect.Slice:
+		if dest.Type().Elem().Kind() == reflect.Uint8 && isText {
+			dest.SetBytes([]byte(text))
+			return nil
+		}
+	case reflect.Bool:
+		if s, ok := src.(int64); ok {
+			dest.SetBool(s != 0)
+			return nil
+		}
+		if isText {
+			b, err := strconv.ParseBool(text)
+			if err != nil {
+				return fmt.Errorf("can not scan value %q to %s: %w", text, dest.Type(), err)
+			}
+			dest.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch s := src.(type) {
+		case int64:
+			i = s
+		case float64:
+			if s != float64(int64(s)) {
+				return scanError(src, dest)
+			}
+			i = int64(s)
+		case bool:
+			if s {
+				i = 1
+			}
+		default:
+			if !isText {
+				return scanError(src, dest)
+			}
+			var err error
+			if i, err = strconv.ParseInt(text, 10, dest.Type().Bits()); err != nil {
+				return fmt.Errorf("can not scan value %q to %s: %w", text, dest.Type(), err)
+			}
+		}
+		if dest.OverflowInt(i) {
+			return fmt.Errorf("can not scan value %v to %s: value out of range", src, dest.Type())
+		}
+		dest.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch s := src.(type) {
+		case int64:
+			if s < 0 {
+				return fmt.Errorf("can not scan value %v to %s: value out of range", src, dest.Type())
+			}
+			u = uint64(s)
+		default:
+			if !isText {
+				return scanError(src, dest)
+			}
+			var err error
+			if u, err = strconv.ParseUint(text, 10, dest.Type().Bits()); err != nil {
+				return fmt.Errorf("can not scan value %q to %s: %w", text, dest.Type(), err)
+			}
+		}
+		if dest.OverflowUint(u) {
+			return fmt.Errorf("can not scan value %v to %s: value out of range", src, dest.Type())
+		}
+		dest.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch s := src.(type) {
+		case float64:
+			f = s
+		case int64:
+			f = float64(s)
+		default:
+			if !isText {
+				return scanError(src, dest)
+			}
+			var err error
+			if f, err = strconv.ParseFloat(text, dest.Type().Bits()); err != nil {
+				return fmt.Errorf("can not scan value %q to %s: %w", text, dest.Type(), err)
+			}
+		}
+		dest.SetFloat(f)
+		return nil
+	}
+
+	if isText {
+		switch dest.Kind() {
+		case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+			return json.Unmarshal([]byte(text), dest.Addr().Interface())
+		}
+	}
+	if sv.Type().ConvertibleTo(dest.Type()) {
+		dest.Set(sv.Convert(dest.Type()))
+		return nil
+	}
+	return scanError(src, dest)
+}
+
+func scanError(src interface{}, dest reflect.Value) error {
+	return fmt.Errorf("can not scan value %v (%T) to %s", src, src, dest.Type())
+}
+
+// parseTimeText parses the time formats commonly returned by drivers as text.
+func parseTimeText(s string) (time.Time, error) {
+	var err error
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", dateFormat} {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
@@ -0,0 +1,141 @@
+package datatypes
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNullJSON(t *testing.T) {
+	type user struct {
+		Name Null[string] `json:"name"`
+		Age  Null[int]    `json:"age"`
+	}
+
+	var u user
+	if err := json.Unmarshal([]byte(`{"name":null}`), &u); err != nil {
+		t.Fatal(err)
+	}
+	if !u.Name.Present || u.Name.Valid {
+		t.Errorf("expected explicit null name, got %+v", u.Name)
+	}
+	if !u.Age.IsAbsent() {
+		t.Errorf("expected absent age, got %+v", u.Age)
+	}
+
+	if err := json.Unmarshal([]byte(`{"name":"joker","age":18}`), &u); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := u.Name.Get(); !ok || v != "joker" || u.Age.OrElse(0) != 18 {
+		t.Errorf("unexpected user %+v", u)
+	}
+
+	u.Age.SetNull()
+	b, err := json.Marshal(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"name":"joker","age":null}` {
+		t.Errorf("unexpected json %s", b)
+	}
+}
+
+func TestNullYAML(t *testing.T) {
+	var n Null[int]
+	err := n.UnmarshalYAML(func(v interface{}) error {
+		*(v.(**int)) = nil
+		return nil
+	})
+	if err != nil || !n.Present || n.Valid {
+		t.Errorf("expected explicit null, got %+v, %v", n, err)
+	}
+
+	err = n.UnmarshalYAML(func(v interface{}) error {
+		x := 3
+		*(v.(**int)) = &x
+		return nil
+	})
+	if err != nil || n.MustGet() != 3 {
+		t.Errorf("expected 3, got %+v, %v", n, err)
+	}
+
+	if v, _ := n.MarshalYAML(); v != 3 {
+		t.Errorf("expected 3, got %v", v)
+	}
+	if v, _ := ExplicitNull[int]().MarshalYAML(); v != nil {
+		t.Errorf("expected nil, got %v", v)
+	}
+	if !(Null[int]{}).IsZero() || ExplicitNull[int]().IsZero() {
+		t.Error("IsZero should report absent values only")
+	}
+}
+
+func TestNullScan(t *testing.T) {
+	type status int8
+	type payload struct {
+		A int `json:"a"`
+	}
+
+	var cases = []struct {
+		dest     interface{ Scan(interface{}) error }
+		src      interface{}
+		expected interface{}
+		fail     bool
+	}{
+		{dest: &Null[string]{}, src: []byte("joker"), expected: "joker"},
+		{dest: &Null[string]{}, src: int64(18), expected: "18"},
+		{dest: &Null[int]{}, src: []byte("18"), expected: 18},
+		{dest: &Null[status]{}, src: int64(3), expected: status(3)},
+		{dest: &Null[status]{}, src: int64(300), fail: true},
+		{dest: &Null[uint]{}, src: int64(-1), fail: true},
+		{dest: &Null[float64]{}, src: int64(2), expected: float64(2)},
+		{dest: &Null[bool]{}, src: int64(1), expected: true},
+		{dest: &Null[bool]{}, src: "false", expected: false},
+		{dest: &Null[int]{}, src: "abc", fail: true},
+		{dest: &Null[[]byte]{}, src: "ab", expected: []byte("ab")},
+		{dest: &Null[payload]{}, src: []byte(`{"a":1}`), expected: payload{A: 1}},
+		{dest: &Null[time.Time]{}, src: "2022-01-02", expected: time.Date(2022, 1, 2, 0, 0, 0, 0, time.Local)},
+		{dest: &Null[Time]{}, src: time.Date(2022, 1, 2, 3, 4, 5, 0, time.Local), expected: Time(time.Date(2022, 1, 2, 3, 4, 5, 0, time.Local))},
+	}
+	for _, c := range cases {
+		err := c.dest.Scan(c.src)
+		if c.fail {
+			if err == nil {
+				t.Errorf("scan %v to %T: expected error", c.src, c.dest)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("scan %v to %T: %v", c.src, c.dest, err)
+			continue
+		}
+		got := reflect.ValueOf(c.dest).Elem().FieldByName("V").Interface()
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("scan %v to %T: expected %v, got %v", c.src, c.dest, c.expected, got)
+		}
+	}
+
+	n := NewNull(1)
+	if err := n.Scan(nil); err != nil || !n.IsNull() || n.IsAbsent() {
+		t.Errorf("expected explicit null, got %+v", n)
+	}
+}
+
+func TestNullValue(t *testing.T) {
+	if v, err := (Null[string]{}).Value(); err != nil || v != nil {
+		t.Errorf("expected nil, got %v, %v", v, err)
+	}
+	if v, err := NewNull(int8(3)).Value(); err != nil || v != int64(3) {
+		t.Errorf("expected 3, got %v, %v", v, err)
+	}
+	if v, err := NewNull(map[string]int{"a": 1}).Value(); err != nil || v != `{"a":1}` {
+		t.Errorf("expected json, got %v, %v", v, err)
+	}
+	if v, err := NewNull(DBStringSlice{"a", "b"}).Value(); err != nil || v != "a,b" {
+		t.Errorf("expected a,b, got %v, %v", v, err)
+	}
+	if p := NewNullPtr[int](nil).Ptr(); p != nil {
+		t.Errorf("expected nil pointer, got %v", p)
+	}
+}
@@ -2,6 +2,7 @@ package datatypes
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"database/sql/driver"
@@ -23,16 +24,20 @@ var (
 )
 
 // UnmarshalJSON implements json unmarshal interface.
-func (t *Time) UnmarshalJSON(data []byte) (err error) {
+func (t *Time) UnmarshalJSON(data []byte) error {
 	if data == nil || string(data) == "null" || string(data) == `""` {
 		return nil
 	}
-	now, err := time.ParseInLocation(`"`+timeFormat+`"`, string(data), time.Local)
+	s, err := strconv.Unquote(string(data))
 	if err != nil {
-		now, err = time.ParseInLocation(`"`+time.RFC3339+`"`, string(data), time.Local)
+		return fmt.Errorf("datatypes: parsing time %s: %w", data, err)
+	}
+	now, err := ParseTime(s, []string{timeFormat, time.RFC3339}, time.Local)
+	if err != nil {
+		return err
 	}
 	*t = Time(now)
-	return
+	return nil
 }
 
 // MarshalJSON implements json marshal interface.
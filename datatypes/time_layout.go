@@ -0,0 +1,248 @@
+package datatypes
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Special layouts for Unix timestamps, usable both as output layout and input layouts.
+const (
+	LayoutUnix      = "unix"
+	LayoutUnixMilli = "unixmilli"
+)
+
+// TimeLayout configures the layouts and location of TimeWithLayout.
+type TimeLayout interface {
+	// Layout is the output layout of JSON, text and String.
+	Layout() string
+	// InputLayouts are tried in order when parsing.
+	InputLayouts() []string
+	// Location is used to parse times without zone and to format times.
+	Location() *time.Location
+}
+
+// defaultInputLayouts do not accept Unix timestamps, otherwise digits such as "20220102" would be
+// read as seconds, layouts list LayoutUnix or LayoutUnixMilli in InputLayouts to opt in.
+var defaultInputLayouts = []string{timeFormat, time.RFC3339Nano, dateFormat}
+
+// Predefined layouts for TimeWithLayout, other layouts can be defined the same way.
+type (
+	// LocalLayout formats "2006-01-02 15:04:05" in time.Local.
+	LocalLayout struct{}
+	// CNLayout formats "2006-01-02 15:04:05" in Asia/Shanghai.
+	CNLayout struct{}
+	// RFC3339Layout formats RFC3339 with nanoseconds in time.Local.
+	RFC3339Layout struct{}
+	// DateLayout formats "2006-01-02" in time.Local.
+	DateLayout struct{}
+	// UnixLayout formats Unix seconds.
+	UnixLayout struct{}
+	// UnixMilliLayout formats Unix milliseconds.
+	UnixMilliLayout struct{}
+)
+
+func (LocalLayout) Layout() string             { return timeFormat }
+func (LocalLayout) InputLayouts() []string     { return defaultInputLayouts }
+func (LocalLayout) Location() *time.Location   { return time.Local }
+func (CNLayout) Layout() string                { return timeFormat }
+func (CNLayout) InputLayouts() []string        { return defaultInputLayouts }
+func (CNLayout) Location() *time.Location      { return SHLocation }
+func (RFC3339Layout) Layout() string           { return time.RFC3339Nano }
+func (RFC3339Layout) InputLayouts() []string   { return defaultInputLayouts }
+func (RFC3339Layout) Location() *time.Location { return time.Local }
+func (DateLayout) Layout() string              { return dateFormat }
+func (DateLayout) InputLayouts() []string      { return defaultInputLayouts }
+func (DateLayout) Location() *time.Location    { return time.Local }
+func (UnixLayout) Layout() string              { return LayoutUnix }
+func (UnixLayout) InputLayouts() []string      { return []string{LayoutUnix, time.RFC3339Nano, timeFormat} }
+func (UnixLayout) Location() *time.Location    { return time.Local }
+func (UnixMilliLayout) Layout() string         { return LayoutUnixMilli }
+func (UnixMilliLayout) InputLayouts() []string {
+	return []string{LayoutUnixMilli, time.RFC3339Nano, timeFormat}
+}
+func (UnixMilliLayout) Location() *time.Location { return time.Local }
+
+// ParseTime parses s with the first matching layout of layouts, times without zone are in loc.
+// LayoutUnix and LayoutUnixMilli parse integer timestamps.
+func ParseTime(s string, layouts []string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	var lastErr error
+	for _, layout := range layouts {
+		switch layout {
+		case LayoutUnix, LayoutUnixMilli:
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			return unixTime(n, layout, loc), nil
+		default:
+			t, err := time.ParseInLocation(layout, s, loc)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			return t, nil
+		}
+	}
+	if lastErr == nil {
+		return time.Time{}, fmt.Errorf("datatypes: parsing time %q: no layouts", s)
+	}
+	return time.Time{}, fmt.Errorf("datatypes: parsing time %q with layouts %q: %w", s, layouts, lastErr)
+}
+
+func unixTime(n int64, layout string, loc *time.Location) time.Time {
+	if layout == LayoutUnixMilli {
+		return time.UnixMilli(n).In(loc)
+	}
+	return time.Unix(n, 0).In(loc)
+}
+
+// TimeWithLayout is a time.Time formatted and parsed by L:
+//
+//	type Order struct {
+//		CreatedAt datatypes.TimeWithLayout[datatypes.RFC3339Layout] `json:"created_at"`
+//		PaidAt    datatypes.TimeWithLayout[datatypes.UnixMilliLayout] `json:"paid_at"`
+//	}
+//
+// The zero value is encoded as JSON null and SQL NULL.
+type TimeWithLayout[L TimeLayout] time.Time
+
+// NewTimeWithLayout returns t as TimeWithLayout in the location of L.
+func NewTimeWithLayout[L TimeLayout](t time.Time) TimeWithLayout[L] {
+	var l L
+	if t.IsZero() {
+		return TimeWithLayout[L]{}
+	}
+	return TimeWithLayout[L](t.In(location(l)))
+}
+
+func location(l TimeLayout) *time.Location {
+	if loc := l.Location(); loc != nil {
+		return loc
+	}
+	return time.Local
+}
+
+// Time returns the time.Time.
+func (t TimeWithLayout[L]) Time() time.Time {
+	return time.Time(t)
+}
+
+func (t TimeWithLayout[L]) IsZero() bool {
+	return time.Time(t).IsZero()
+}
+
+func (t TimeWithLayout[L]) String() string {
+	return string(t.appendFormat(nil))
+}
+
+func (t TimeWithLayout[L]) appendFormat(b []byte) []byte {
+	var l L
+	tt := time.Time(t).In(location(l))
+	switch layout := l.Layout(); layout {
+	case LayoutUnix:
+		return strconv.AppendInt(b, tt.Unix(), 10)
+	case LayoutUnixMilli:
+		return strconv.AppendInt(b, tt.UnixMilli(), 10)
+	default:
+		return tt.AppendFormat(b, layout)
+	}
+}
+
+func (t *TimeWithLayout[L]) parse(s string) error {
+	var l L
+	tt, err := ParseTime(s, l.InputLayouts(), location(l))
+	if err != nil {
+		return err
+	}
+	*t = TimeWithLayout[L](tt)
+	return nil
+}
+
+func isUnixLayout(layout string) bool {
+	return layout == LayoutUnix || layout == LayoutUnixMilli
+}
+
+// MarshalJSON implements the json.Marshaler interface, Unix layouts are encoded as numbers.
+func (t TimeWithLayout[L]) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+
+	var l L
+	if isUnixLayout(l.Layout()) {
+		return t.appendFormat(nil), nil
+	}
+	b := append([]byte{'"'}, t.appendFormat(nil)...)
+	return append(b, '"'), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, both strings and numbers are accepted.
+func (t *TimeWithLayout[L]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) || bytes.Equal(data, []byte(`""`)) {
+		*t = TimeWithLayout[L]{}
+		return nil
+	}
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return err
+		}
+		return t.parse(s)
+	}
+	return t.parse(string(data))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (t TimeWithLayout[L]) MarshalText() ([]byte, error) {
+	if t.IsZero() {
+		return []byte{}, nil
+	}
+	return t.appendFormat(nil), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (t *TimeWithLayout[L]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*t = TimeWithLayout[L]{}
+		return nil
+	}
+	return t.parse(string(text))
+}
+
+// Value implements the driver.Valuer interface.
+func (t TimeWithLayout[L]) Value() (driver.Value, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	var l L
+	return time.Time(t).In(location(l)), nil
+}
+
+// Scan implements the sql.Scanner interface, accepting time.Time, string, []byte and int64.
+// int64 is read as Unix milliseconds if the layout of L is LayoutUnixMilli, otherwise as Unix seconds.
+func (t *TimeWithLayout[L]) Scan(v interface{}) error {
+	var l L
+	switch value := v.(type) {
+	case nil:
+		*t = TimeWithLayout[L]{}
+	case time.Time:
+		*t = NewTimeWithLayout[L](value)
+	case int64:
+		*t = TimeWithLayout[L](unixTime(value, l.Layout(), location(l)))
+	case []byte:
+		return t.UnmarshalText(value)
+	case string:
+		return t.UnmarshalText([]byte(value))
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to %T", v, v, t)
+	}
+	return nil
+}
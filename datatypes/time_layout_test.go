@@ -0,0 +1,112 @@
+package datatypes
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimeWithLayoutJSON(t *testing.T) {
+	type order struct {
+		CreatedAt TimeWithLayout[CNLayout]        `json:"created_at"`
+		PaidAt    TimeWithLayout[UnixMilliLayout] `json:"paid_at"`
+		ShipAt    TimeWithLayout[RFC3339Layout]   `json:"ship_at"`
+	}
+
+	var o order
+	err := json.Unmarshal([]byte(`{"created_at":"2022-01-02T03:04:05Z","paid_at":1641092645000,"ship_at":null}`), &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"created_at":"2022-01-02 11:04:05","paid_at":1641092645000,"ship_at":null}` {
+		t.Errorf("unexpected json %s", b)
+	}
+
+	var cases = []string{
+		`{"created_at":"2022-01-02"}`,
+		`{"created_at":"2022-01-02 11:04:05"}`,
+	}
+	for _, c := range cases {
+		if err = json.Unmarshal([]byte(c), &o); err != nil {
+			t.Errorf("unmarshal %s: %v", c, err)
+		}
+	}
+	if !o.CreatedAt.Time().Equal(time.Unix(1641092645, 0)) {
+		t.Errorf("unexpected time %v", o.CreatedAt)
+	}
+
+	before := o.CreatedAt
+	for _, c := range []string{`{"created_at":"yesterday"}`, `{"created_at":1641092645}`, `{"created_at":"20220102"}`} {
+		if err = json.Unmarshal([]byte(c), &o); err == nil {
+			t.Errorf("unmarshal %s: expected parse error", c)
+		}
+	}
+	if o.CreatedAt != before {
+		t.Errorf("failed parse should not modify the time, got %v", o.CreatedAt)
+	}
+
+	var legacy Time
+	if err = json.Unmarshal([]byte(`"yesterday"`), &legacy); err == nil || !legacy.IsZero() {
+		t.Errorf("expected parse error, got %v, %v", legacy, err)
+	}
+}
+
+type unixInputLayout struct{ CNLayout }
+
+func (unixInputLayout) InputLayouts() []string { return []string{timeFormat, time.RFC3339Nano, dateFormat, LayoutUnix} }
+
+func TestTimeWithLayoutUnixInput(t *testing.T) {
+	var tt TimeWithLayout[unixInputLayout]
+	if err := json.Unmarshal([]byte(`1641092645`), &tt); err != nil || !tt.Time().Equal(time.Unix(1641092645, 0)) {
+		t.Errorf("unmarshal unix seconds: got %v, %v", tt, err)
+	}
+	if err := tt.UnmarshalText([]byte("2022-01-02 11:04:05")); err != nil || !tt.Time().Equal(time.Unix(1641092645, 0)) {
+		t.Errorf("unmarshal text: got %v, %v", tt, err)
+	}
+}
+
+func TestTimeWithLayoutScan(t *testing.T) {
+	expected := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var cases = []interface{}{
+		expected,
+		"2022-01-02T03:04:05Z",
+		[]byte("2022-01-02T03:04:05Z"),
+		expected.Unix(),
+	}
+	for _, c := range cases {
+		var tt TimeWithLayout[DateLayout]
+		if err := tt.Scan(c); err != nil {
+			t.Errorf("scan %v: %v", c, err)
+			continue
+		}
+		if !tt.Time().Equal(expected) {
+			t.Errorf("scan %v: expected %v, got %v", c, expected, tt.Time())
+		}
+	}
+
+	var ms TimeWithLayout[UnixMilliLayout]
+	if err := ms.Scan(expected.UnixMilli()); err != nil || !ms.Time().Equal(expected) {
+		t.Errorf("scan millis: got %v, %v", ms.Time(), err)
+	}
+	if v, _ := ms.Value(); !v.(time.Time).Equal(expected) {
+		t.Errorf("unexpected value %v", v)
+	}
+
+	if err := ms.Scan(nil); err != nil || !ms.IsZero() {
+		t.Errorf("expected zero time, got %v, %v", ms.Time(), err)
+	}
+	if v, _ := ms.Value(); v != nil {
+		t.Errorf("expected nil value, got %v", v)
+	}
+	if err := ms.Scan("abc"); err == nil {
+		t.Error("expected parse error")
+	}
+	if err := ms.Scan(1.5); err == nil {
+		t.Error("expected type error")
+	}
+}
@@ -0,0 +1,232 @@
+package datatypes
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/joker-circus/gotools/timeutil"
+)
+
+// TimeRange is the half-open interval [Start, End).
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// NewTimeRange returns [start, end), start and end are swapped if end is before start.
+func NewTimeRange(start, end time.Time) TimeRange {
+	if end.Before(start) {
+		start, end = end, start
+	}
+	return TimeRange{Start: start, End: end}
+}
+
+// DateRange returns the range from the start of start to the start of the day after end, in loc.
+func DateRange(start, end Date, loc *time.Location) TimeRange {
+	return NewTimeRange(start.In(loc), end.AddDays(1).In(loc))
+}
+
+// IsEmpty reports whether the range contains no time.
+func (r TimeRange) IsEmpty() bool {
+	return !r.Start.Before(r.End)
+}
+
+func (r TimeRange) Duration() time.Duration {
+	if r.IsEmpty() {
+		return 0
+	}
+	return r.End.Sub(r.Start)
+}
+
+// Contains reports whether t is in [Start, End).
+func (r TimeRange) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// ContainsRange reports whether r1 is within r, an empty r1 is contained by any range.
+func (r TimeRange) ContainsRange(r1 TimeRange) bool {
+	if r1.IsEmpty() {
+		return true
+	}
+	return !r1.Start.Before(r.Start) && !r1.End.After(r.End)
+}
+
+// Overlaps reports whether r and r1 have time in common, adjacent ranges do not overlap.
+func (r TimeRange) Overlaps(r1 TimeRange) bool {
+	return r.Start.Before(r1.End) && r1.Start.Before(r.End)
+}
+
+// Intersect returns the common part of r and r1, false if they do not overlap.
+func (r TimeRange) Intersect(r1 TimeRange) (TimeRange, bool) {
+	if !r.Overlaps(r1) {
+		return TimeRange{}, false
+	}
+
+	start, end := r.Start, r.End
+	if r1.Start.After(start) {
+		start = r1.Start
+	}
+	if r1.End.Before(end) {
+		end = r1.End
+	}
+	return TimeRange{Start: start, End: end}, true
+}
+
+// Split splits r into ranges of at most interval, by timeutil.RangeTime.
+func (r TimeRange) Split(interval time.Duration) []TimeRange {
+	var ranges []TimeRange
+	if interval <= 0 {
+		return ranges
+	}
+	timeutil.RangeTime(r.Start, r.End, interval, func(t1, t2 time.Time) bool {
+		ranges = append(ranges, TimeRange{Start: t1, End: t2})
+		return true
+	})
+	return ranges
+}
+
+// SplitByDay splits r at the midnights in the location of Start,
+// the first and last ranges may be partial days.
+func (r TimeRange) SplitByDay() []TimeRange {
+	return r.splitBy(timeutil.GetZeroTime, func(t time.Time) time.Time { return t.AddDate(0, 0, 1) })
+}
+
+// SplitByMonth splits r at the first day of months in the location of Start,
+// the first and last ranges may be partial months.
+func (r TimeRange) SplitByMonth() []TimeRange {
+	return r.splitBy(timeutil.GetFirstDateOfMonth, func(t time.Time) time.Time { return t.AddDate(0, 1, 0) })
+}
+
+func (r TimeRange) splitBy(truncate, next func(t time.Time) time.Time) []TimeRange {
+	var ranges []TimeRange
+	for start := r.Start; start.Before(r.End); {
+		end := next(truncate(start))
+		if end.After(r.End) {
+			end = r.End
+		}
+		ranges = append(ranges, TimeRange{Start: start, End: end})
+		start = end
+	}
+	return ranges
+}
+
+// String returns the ISO 8601 interval "start/end" in RFC3339.
+func (r TimeRange) String() string {
+	return r.Start.Format(time.RFC3339Nano) + "/" + r.End.Format(time.RFC3339Nano)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (r TimeRange) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// ErrUnboundedTimeRange is returned when parsing a range without a start or end, which TimeRange can not hold.
+var ErrUnboundedTimeRange = errors.New("datatypes: unbounded time range")
+
+// timeRangeLayouts are the layouts of the range bounds, PostgreSQL writes the offset as "+08" or "+05:30".
+var timeRangeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07",
+	"2006-01-02 15:04:05.999999999-07:00",
+	timeFormat,
+	dateFormat,
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface, accepting "start/end" and
+// the PostgreSQL range literals such as "[start,end)", "(start,end]" and "empty".
+// Exclusive starts and inclusive ends are moved by a microsecond, the PostgreSQL timestamp resolution,
+// unbounded and infinite bounds are rejected with ErrUnboundedTimeRange.
+func (r *TimeRange) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if s == "empty" {
+		*r = TimeRange{}
+		return nil
+	}
+
+	sep := "/"
+	var exclusiveStart, inclusiveEnd bool
+	if len(s) >= 2 && strings.ContainsRune("[(", rune(s[0])) && strings.ContainsRune("])", rune(s[len(s)-1])) {
+		exclusiveStart, inclusiveEnd = s[0] == '(', s[len(s)-1] == ']'
+		s, sep = s[1:len(s)-1], ","
+	}
+
+	start, end, ok := strings.Cut(s, sep)
+	if !ok {
+		return fmt.Errorf("datatypes: parsing time range %q: missing %q", text, sep)
+	}
+	st, err := parseRangeBound(start)
+	if err != nil {
+		return fmt.Errorf("datatypes: parsing time range %q: %w", text, err)
+	}
+	et, err := parseRangeBound(end)
+	if err != nil {
+		return fmt.Errorf("datatypes: parsing time range %q: %w", text, err)
+	}
+	if exclusiveStart {
+		st = st.Add(time.Microsecond)
+	}
+	if inclusiveEnd {
+		et = et.Add(time.Microsecond)
+	}
+	*r = TimeRange{Start: st, End: et}
+	return nil
+}
+
+func parseRangeBound(s string) (time.Time, error) {
+	s = strings.Trim(strings.TrimSpace(s), `"`)
+	switch s {
+	case "", "infinity", "-infinity":
+		return time.Time{}, ErrUnboundedTimeRange
+	}
+	return ParseTime(s, timeRangeLayouts, time.Local)
+}
+
+// MarshalJSON implements the json.Marshaler interface, encoded as {"start": ..., "end": ...}.
+func (r TimeRange) MarshalJSON() ([]byte, error) {
+	type timeRange TimeRange
+	return json.Marshal(timeRange(r))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting the object or a text string.
+func (r *TimeRange) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return r.UnmarshalText([]byte(s))
+	}
+
+	type timeRange TimeRange
+	var tr timeRange
+	if err := json.Unmarshal(data, &tr); err != nil {
+		return err
+	}
+	*r = TimeRange(tr)
+	return nil
+}
+
+// Value implements the driver.Valuer interface, stored as the PostgreSQL range literal
+// `["start","end")` which is also readable as text in other databases.
+func (r TimeRange) Value() (driver.Value, error) {
+	return fmt.Sprintf(`[%q,%q)`, r.Start.Format(time.RFC3339Nano), r.End.Format(time.RFC3339Nano)), nil
+}
+
+// Scan implements the sql.Scanner interface, accepting string and []byte.
+func (r *TimeRange) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		*r = TimeRange{}
+	case []byte:
+		return r.UnmarshalText(value)
+	case string:
+		return r.UnmarshalText([]byte(value))
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to %T", v, v, r)
+	}
+	return nil
+}
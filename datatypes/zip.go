@@ -6,24 +6,22 @@ import (
 	"database/sql/driver"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
 	"io"
 )
 
+// DBZipData is stored as gzip compressed JSON without header, so that the rows stay
+// readable by the former versions. Use DBCodecData to store with another codec.
 type DBZipData[T any] []T
 
+// FromZipByte decodes data written by ToZipBytes, the codec is read from the header,
+// legacy data without header is decoded as gzip compressed JSON.
 func (za *DBZipData[T]) FromZipByte(bs []byte) error {
 	if bs == nil {
 		return nil
 	}
 
-	b, err := UnGzip(bs)
-	if err != nil {
-		return err
-	}
-
 	var znf DBZipData[T]
-	err = json.Unmarshal(b, &znf)
+	err := DecodeZip(bs, &znf)
 	if err != nil {
 		return err
 	}
@@ -31,6 +29,7 @@ func (za *DBZipData[T]) FromZipByte(bs []byte) error {
 	return nil
 }
 
+// ToZipBytes encodes the data as gzip compressed JSON without header.
 func (za DBZipData[T]) ToZipBytes() ([]byte, error) {
 	if za == nil {
 		return nil, nil
@@ -48,24 +47,12 @@ func (za DBZipData[T]) Value() (driver.Value, error) {
 }
 
 func (za *DBZipData[T]) Scan(v interface{}) error {
-	var val DBZipData[T]
-	var fields T
-	if v == nil {
-		*za = val
-		return nil
-	}
-
-	b, ok := v.([]byte)
-	if !ok {
-		return fmt.Errorf("can not scan value %v to %T", v, fields)
-	}
-
-	if len(b) == 0 {
-		*za = val
-		return nil
+	var val []T
+	if err := scanZip(v, &val); err != nil {
+		return err
 	}
-
-	return za.FromZipByte(b)
+	*za = val
+	return nil
 }
 
 func Gzip(data []byte) ([]byte, error) {
@@ -0,0 +1,360 @@
+package datatypes
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// CompressorID identifies a compression algorithm in the zip header, 0-15.
+type CompressorID uint8
+
+const (
+	CompressNone CompressorID = iota
+	CompressGzip
+	CompressDeflate
+	CompressZlib
+	// CompressZstd, CompressSnappy and CompressLZ4 are reserved ids with no built-in
+	// implementation, since they are not in the standard library. Register one by
+	// RegisterCompressor and define a ZipCodecProvider to use them.
+	CompressZstd
+	CompressSnappy
+	CompressLZ4
+)
+
+// SerializerID identifies a serialization format in the zip header, 0-7.
+type SerializerID uint8
+
+const (
+	SerializeJSON SerializerID = iota
+	SerializeGob
+	// SerializeMsgpack is a reserved id, register the implementation by RegisterSerializer.
+	SerializeMsgpack
+)
+
+const (
+	maxCompressorID = 0x0f
+	maxSerializerID = 0x07
+	// zipHeaderFlag marks data with a zip header. Legacy data is gzip without header,
+	// which always starts with 0x1f, so the flag never collides with it.
+	zipHeaderFlag = 0x80
+)
+
+// ErrCodecNotRegistered is returned when the compressor or serializer of a codec is not registered.
+var ErrCodecNotRegistered = errors.New("datatypes: zip codec is not registered")
+
+var compressorNames = [...]string{
+	CompressNone:    "CompressNone",
+	CompressGzip:    "CompressGzip",
+	CompressDeflate: "CompressDeflate",
+	CompressZlib:    "CompressZlib",
+	CompressZstd:    "CompressZstd",
+	CompressSnappy:  "CompressSnappy",
+	CompressLZ4:     "CompressLZ4",
+}
+
+func (id CompressorID) String() string {
+	if int(id) < len(compressorNames) {
+		return compressorNames[id]
+	}
+	return fmt.Sprintf("CompressorID(%d)", uint8(id))
+}
+
+var serializerNames = [...]string{
+	SerializeJSON:    "SerializeJSON",
+	SerializeGob:     "SerializeGob",
+	SerializeMsgpack: "SerializeMsgpack",
+}
+
+func (id SerializerID) String() string {
+	if int(id) < len(serializerNames) {
+		return serializerNames[id]
+	}
+	return fmt.Sprintf("SerializerID(%d)", uint8(id))
+}
+
+// Compressor compresses the serialized data of DBZipData.
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// Serializer serializes the value of DBZipData before compression.
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// ZipCodec is the compression and serialization used to store DBZipData.
+type ZipCodec struct {
+	Compressor CompressorID
+	Serializer SerializerID
+}
+
+// ZipCodec implements ZipCodecProvider.
+func (c ZipCodec) ZipCodec() ZipCodec {
+	return c
+}
+
+func (c ZipCodec) header() byte {
+	return zipHeaderFlag | byte(c.Serializer)<<4 | byte(c.Compressor)
+}
+
+var (
+	codecMu     sync.RWMutex
+	compressors = [maxCompressorID + 1]Compressor{
+		CompressNone:    noneCompressor{},
+		CompressGzip:    gzipCompressor{},
+		CompressDeflate: deflateCompressor{},
+		CompressZlib:    zlibCompressor{},
+	}
+	serializers = [maxSerializerID + 1]Serializer{
+		SerializeJSON: jsonSerializer{},
+		SerializeGob:  gobSerializer{},
+	}
+)
+
+// RegisterCompressor registers the compressor of id, replacing the existing one.
+// Usage:
+//
+//	datatypes.RegisterCompressor(datatypes.CompressZstd, zstdCompressor{})
+func RegisterCompressor(id CompressorID, c Compressor) {
+	if id > maxCompressorID {
+		panic(fmt.Sprintf("datatypes: compressor id %d out of range", id))
+	}
+	codecMu.Lock()
+	compressors[id] = c
+	codecMu.Unlock()
+}
+
+// RegisterSerializer registers the serializer of id, replacing the existing one.
+func RegisterSerializer(id SerializerID, s Serializer) {
+	if id > maxSerializerID {
+		panic(fmt.Sprintf("datatypes: serializer id %d out of range", id))
+	}
+	codecMu.Lock()
+	serializers[id] = s
+	codecMu.Unlock()
+}
+
+func getCodec(c ZipCodec) (Compressor, Serializer, error) {
+	if c.Compressor > maxCompressorID {
+		return nil, nil, fmt.Errorf("datatypes: compressor id %d out of range", c.Compressor)
+	}
+	if c.Serializer > maxSerializerID {
+		return nil, nil, fmt.Errorf("datatypes: serializer id %d out of range", c.Serializer)
+	}
+
+	codecMu.RLock()
+	compressor, serializer := compressors[c.Compressor], serializers[c.Serializer]
+	codecMu.RUnlock()
+	if compressor == nil {
+		return nil, nil, fmt.Errorf("%w: compressor %s, call datatypes.RegisterCompressor(datatypes.%s, ...) first",
+			ErrCodecNotRegistered, c.Compressor, c.Compressor)
+	}
+	if serializer == nil {
+		return nil, nil, fmt.Errorf("%w: serializer %s, call datatypes.RegisterSerializer(datatypes.%s, ...) first",
+			ErrCodecNotRegistered, c.Serializer, c.Serializer)
+	}
+	return compressor, serializer, nil
+}
+
+// EncodeZip serializes and compresses v with codec, prefixed with a header byte recording the codec.
+func EncodeZip(codec ZipCodec, v interface{}) ([]byte, error) {
+	compressor, serializer, err := getCodec(codec)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := serializer.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	data, err = compressor.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{codec.header()}, data...), nil
+}
+
+// DecodeZip decodes the data written by EncodeZip into v, the codec is read from the header.
+// Data without header is decoded as gzip compressed JSON written by the former DBZipData.
+func DecodeZip(data []byte, v interface{}) error {
+	codec := ZipCodec{Compressor: CompressGzip, Serializer: SerializeJSON}
+	if len(data) > 0 && data[0]&zipHeaderFlag != 0 {
+		codec = ZipCodec{
+			Compressor: CompressorID(data[0] & maxCompressorID),
+			Serializer: SerializerID(data[0] >> 4 & maxSerializerID),
+		}
+		data = data[1:]
+	}
+
+	compressor, serializer, err := getCodec(codec)
+	if err != nil {
+		return err
+	}
+	data, err = compressor.Decompress(data)
+	if err != nil {
+		return err
+	}
+	return serializer.Unmarshal(data, v)
+}
+
+// ZipCodecProvider provides the codec of DBCodecData by its type.
+type ZipCodecProvider interface {
+	ZipCodec() ZipCodec
+}
+
+// Codec providers of the built-in codecs for DBCodecData. Providers of the registered
+// codecs are defined the same way:
+//
+//	datatypes.RegisterCompressor(datatypes.CompressZstd, zstdCompressor{})
+//
+//	type ZstdJSON struct{}
+//
+//	func (ZstdJSON) ZipCodec() datatypes.ZipCodec {
+//		return datatypes.ZipCodec{Compressor: datatypes.CompressZstd, Serializer: datatypes.SerializeJSON}
+//	}
+type (
+	GzipJSON    struct{}
+	DeflateJSON struct{}
+	ZlibJSON    struct{}
+	GzipGob     struct{}
+)
+
+func (GzipJSON) ZipCodec() ZipCodec    { return ZipCodec{CompressGzip, SerializeJSON} }
+func (DeflateJSON) ZipCodec() ZipCodec { return ZipCodec{CompressDeflate, SerializeJSON} }
+func (ZlibJSON) ZipCodec() ZipCodec    { return ZipCodec{CompressZlib, SerializeJSON} }
+func (GzipGob) ZipCodec() ZipCodec     { return ZipCodec{CompressGzip, SerializeGob} }
+
+// DBCodecData is DBZipData stored with the codec provided by C, the codec is chosen per column:
+//
+//	type Report struct {
+//		Items datatypes.DBCodecData[Item, datatypes.DeflateJSON]
+//	}
+//
+// Rows written by any codec, including DBZipData rows without header, are decoded by the
+// header. The rows written by DBCodecData are not readable by the versions before it.
+type DBCodecData[T any, C ZipCodecProvider] []T
+
+// Value implements the driver.Valuer interface. It returns ErrCodecNotRegistered if the
+// compressor or serializer of C is not registered, e.g. CompressZstd.
+func (d DBCodecData[T, C]) Value() (driver.Value, error) {
+	if d == nil {
+		return nil, nil
+	}
+	var c C
+	return EncodeZip(c.ZipCodec(), []T(d))
+}
+
+func (d *DBCodecData[T, C]) Scan(v interface{}) error {
+	var data []T
+	if err := scanZip(v, &data); err != nil {
+		return err
+	}
+	*d = data
+	return nil
+}
+
+func scanZip[T any](v interface{}, dest *[]T) error {
+	if v == nil {
+		return nil
+	}
+
+	b, ok := v.([]byte)
+	if !ok {
+		var fields T
+		return fmt.Errorf("can not scan value %v to %T", v, fields)
+	}
+	if len(b) == 0 {
+		return nil
+	}
+	return DecodeZip(b, dest)
+}
+
+type noneCompressor struct{}
+
+func (noneCompressor) Compress(data []byte) ([]byte, error)   { return data, nil }
+func (noneCompressor) Decompress(data []byte) ([]byte, error) { return data, nil }
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error)   { return Gzip(data) }
+func (gzipCompressor) Decompress(data []byte) ([]byte, error) { return UnGzip(data) }
+
+type deflateCompressor struct{}
+
+func (deflateCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	return compressWith(&buf, w, data)
+}
+
+func (deflateCompressor) Decompress(data []byte) ([]byte, error) {
+	return decompressWith(flate.NewReader(bytes.NewReader(data)))
+}
+
+type zlibCompressor struct{}
+
+func (zlibCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	return compressWith(&buf, zlib.NewWriter(&buf), data)
+}
+
+func (zlibCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return decompressWith(r)
+}
+
+func compressWith(buf *bytes.Buffer, w io.WriteCloser, data []byte) ([]byte, error) {
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressWith(r io.ReadCloser) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return nil, err
+	}
+	if err := r.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type jsonSerializer struct{}
+
+func (jsonSerializer) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonSerializer) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type gobSerializer struct{}
+
+func (gobSerializer) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobSerializer) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
@@ -0,0 +1,109 @@
+package datatypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type reverseCompressor struct{}
+
+func (reverseCompressor) Compress(data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		out[len(data)-1-i] = b
+	}
+	return out, nil
+}
+
+func (c reverseCompressor) Decompress(data []byte) ([]byte, error) {
+	return c.Compress(data)
+}
+
+type snappyJSON struct{}
+
+func (snappyJSON) ZipCodec() ZipCodec { return ZipCodec{CompressSnappy, SerializeJSON} }
+
+type zstdJSON struct{}
+
+func (zstdJSON) ZipCodec() ZipCodec { return ZipCodec{CompressZstd, SerializeJSON} }
+
+func TestDBZipDataLegacy(t *testing.T) {
+	data, _ := json.Marshal([]string{"a", "b"})
+	legacy, err := Gzip(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var z DBZipData[string]
+	if err = z.Scan(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(z, DBZipData[string]{"a", "b"}) {
+		t.Errorf("unexpected data %v", z)
+	}
+
+	v, err := z.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// written without header, readable by the former versions
+	if !bytes.Equal(v.([]byte), legacy) {
+		t.Errorf("expected legacy gzip data, got %x", v)
+	}
+	unzipped, err := UnGzip(v.([]byte))
+	if err != nil || !bytes.Equal(unzipped, data) {
+		t.Errorf("unexpected gzip data %q, %v", unzipped, err)
+	}
+}
+
+func TestDBCodecData(t *testing.T) {
+	RegisterCompressor(CompressSnappy, reverseCompressor{})
+	defer RegisterCompressor(CompressSnappy, nil)
+
+	type item struct {
+		ID   int
+		Name string
+	}
+	items := []item{{1, "a"}, {2, "b"}}
+
+	deflate := DBCodecData[item, DeflateJSON](items)
+	v, err := deflate.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Any codec can be read back regardless of the column type.
+	var z DBZipData[item]
+	if err = z.Scan(v); err != nil || !reflect.DeepEqual([]item(z), items) {
+		t.Errorf("deflate: unexpected %v, %v", z, err)
+	}
+
+	gobData, err := DBCodecData[item, GzipGob](items).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g DBCodecData[item, ZlibJSON]
+	if err = g.Scan(gobData); err != nil || !reflect.DeepEqual([]item(g), items) {
+		t.Errorf("gob: unexpected %v, %v", g, err)
+	}
+
+	snappy, err := DBCodecData[item, snappyJSON](items).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(snappy.([]byte)[1:], []byte(`]}`)) {
+		t.Errorf("expected registered compressor to be used, got %q", snappy)
+	}
+	var s DBCodecData[item, snappyJSON]
+	if err = s.Scan(snappy); err != nil || !reflect.DeepEqual([]item(s), items) {
+		t.Errorf("snappy: unexpected %v, %v", s, err)
+	}
+
+	_, err = (DBCodecData[item, zstdJSON](items)).Value()
+	if !errors.Is(err, ErrCodecNotRegistered) || !strings.Contains(err.Error(), "RegisterCompressor(datatypes.CompressZstd") {
+		t.Errorf("expected unregistered compressor error, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/joker-circus/gotools/dbutil/insert"
+)
+
+// condition is a filter or having expression with bound arguments.
+type condition struct {
+	// column is quoted by Build and prepended to expr if not empty.
+	column string
+	expr   string
+	args   []interface{}
+}
+
+// raw reports whether the condition is added without arguments.
+func (c condition) raw() bool {
+	return c.column == "" && len(c.args) == 0
+}
+
+// newCondition expands the slice arguments of expr to placeholder lists.
+func newCondition(column, expr string, args []interface{}) (condition, bool) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return condition{}, false
+	}
+
+	cond := condition{column: column}
+	var sb strings.Builder
+	idx := 0
+	rangePlaceholders(expr, func(s string, placeholder bool) {
+		if !placeholder || idx >= len(args) {
+			sb.WriteString(s)
+			return
+		}
+
+		arg := args[idx]
+		idx++
+		values, ok := expandSlice(arg)
+		if !ok {
+			sb.WriteString(s)
+			cond.args = append(cond.args, arg)
+			return
+		}
+		if len(values) == 0 {
+			sb.WriteString("null")
+			return
+		}
+		sb.WriteString(placeholders(len(values)))
+		cond.args = append(cond.args, values...)
+	})
+	// more arguments than placeholders are kept as they are
+	cond.args = append(cond.args, args[idx:]...)
+	cond.expr = sb.String()
+	return cond, true
+}
+
+// expandSlice returns the elements of a slice or an array argument,
+// []byte and *Query are single values.
+func expandSlice(arg interface{}) ([]interface{}, bool) {
+	switch arg.(type) {
+	case nil, []byte, *Query:
+		return nil, false
+	}
+
+	rv := reflect.ValueOf(arg)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		values[i] = rv.Index(i).Interface()
+	}
+	return values, true
+}
+
+// placeholders returns n "?" separated by commas.
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?,", n-1) + "?"
+}
+
+// rangePlaceholders splits expr into the text and the "?" placeholders,
+// "?" in string literals is text.
+func rangePlaceholders(expr string, f func(s string, placeholder bool)) {
+	start, quoted := 0, false
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '\'':
+			quoted = !quoted
+		case '?':
+			if quoted {
+				continue
+			}
+			if start < i {
+				f(expr[start:i], false)
+			}
+			f("?", true)
+			start = i + 1
+		}
+	}
+	if start < len(expr) {
+		f(expr[start:], false)
+	}
+}
+
+// sqlBuilder numbers the placeholders across the whole query and collects
+// the arguments. In explain mode the arguments are inlined and the
+// identifiers are not quoted.
+type sqlBuilder struct {
+	dialect Dialect
+	explain bool
+	args    []interface{}
+}
+
+func (b *sqlBuilder) ident(s string) string {
+	if b.explain {
+		return s
+	}
+	return quoteIdentifier(b.dialect, s)
+}
+
+// table quotes the table name and the alias, e.g. "orders as o" or "orders o".
+func (b *sqlBuilder) table(s string) string {
+	s = strings.TrimSpace(s)
+	if name, alias := splitAlias(s); alias != "" {
+		return b.alias(b.ident(name), alias)
+	}
+	if fields := strings.Fields(s); len(fields) == 2 {
+		return b.ident(fields[0]) + " " + b.ident(fields[1])
+	}
+	return b.ident(s)
+}
+
+func (b *sqlBuilder) alias(expr, alias string) string {
+	if alias == "" {
+		return expr
+	}
+	return expr + " as " + b.ident(alias)
+}
+
+// subquery builds the query in parentheses.
+func (b *sqlBuilder) subquery(q *Query) (string, error) {
+	sql, err := q.buildWith(b)
+	if err != nil {
+		return "", err
+	}
+	return "(" + strings.TrimSpace(sql) + ")", nil
+}
+
+func (b *sqlBuilder) conditions(conds []condition, sep string) (string, error) {
+	exprs := make([]string, 0, len(conds))
+	for _, cond := range conds {
+		expr, err := b.condition(cond)
+		if err != nil {
+			return "", err
+		}
+		exprs = append(exprs, expr)
+	}
+	return strings.Join(exprs, sep), nil
+}
+
+// condition replaces the placeholders, a *Query argument is built as
+// a subquery.
+func (b *sqlBuilder) condition(cond condition) (string, error) {
+	expr := cond.expr
+	if cond.column != "" {
+		expr = b.ident(cond.column) + expr
+	}
+	if len(cond.args) == 0 {
+		return expr, nil
+	}
+
+	var (
+		sb  strings.Builder
+		err error
+	)
+	idx := 0
+	rangePlaceholders(expr, func(s string, placeholder bool) {
+		if !placeholder || idx >= len(cond.args) || err != nil {
+			sb.WriteString(s)
+			return
+		}
+
+		arg := cond.args[idx]
+		idx++
+		if sub, ok := arg.(*Query); ok {
+			s, err = b.subquery(sub)
+			sb.WriteString(s)
+			return
+		}
+		if b.explain {
+			sb.WriteString(insert.ExplainSQL("?", nil, "'", arg))
+			return
+		}
+		b.args = append(b.args, arg)
+		sb.WriteString(b.dialect.Placeholder(len(b.args)))
+	})
+	return sb.String(), err
+}
@@ -0,0 +1,184 @@
+package dbmock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// DriverName 为注册到 database/sql 的驱动名称
+const DriverName = "gotools-dbmock"
+
+var (
+	registerOnce sync.Once
+	mockSeq      atomic.Int64
+	mocks        sync.Map // map[dsn]*Mock
+)
+
+type mockDriver struct{}
+
+func (mockDriver) Open(dsn string) (driver.Conn, error) {
+	m, ok := mocks.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("dbmock: unknown dsn %q", dsn)
+	}
+	return &conn{mock: m.(*Mock)}, nil
+}
+
+// New 返回使用 Mock 的 *sql.DB，*sql.DB 实现了 gotools.DB，
+// 可直接用于 gotools.Query、QueryMap 及 insert.ExecBatches 等。
+// Usage:
+//
+//	db, mock := dbmock.New()
+//	defer db.Close()
+//	mock.ExpectQuery("select .* from users").WithArgs(18).
+//		WillReturnRows(dbmock.NewRows("id", "name").AddRow(1, "joker"))
+//	_, rows, err := gotools.Query(db, "select id, name from users where age >= ?", 18)
+//	err = mock.ExpectationsWereMet()
+func New() (*sql.DB, *Mock) {
+	registerOnce.Do(func() {
+		sql.Register(DriverName, mockDriver{})
+	})
+
+	m := newMock()
+	dsn := "dbmock_" + strconv.FormatInt(mockSeq.Add(1), 10)
+	mocks.Store(dsn, m)
+
+	db, _ := sql.Open(DriverName, dsn)
+	m.close = func() { mocks.Delete(dsn) }
+	return db, m
+}
+
+type conn struct {
+	mock *Mock
+}
+
+var (
+	_ driver.Conn               = (*conn)(nil)
+	_ driver.ConnBeginTx        = (*conn)(nil)
+	_ driver.ExecerContext      = (*conn)(nil)
+	_ driver.QueryerContext     = (*conn)(nil)
+	_ driver.ConnPrepareContext = (*conn)(nil)
+)
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return &stmt{conn: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.mock.begin(); err != nil {
+		return nil, err
+	}
+	return &tx{mock: c.mock}, nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.mock.exec(ctx, query, namedValues(args))
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.mock.query(ctx, query, namedValues(args))
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput 返回 -1，参数个数不做检查
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.mock.exec(context.Background(), s.query, args)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.mock.query(context.Background(), s.query, args)
+}
+
+type tx struct {
+	mock *Mock
+}
+
+func (t *tx) Commit() error {
+	return t.mock.commit()
+}
+
+func (t *tx) Rollback() error {
+	return t.mock.rollback()
+}
+
+// rows 按顺序返回脚本中的结果集
+type rows struct {
+	sets []*Rows
+	set  int
+	pos  int
+}
+
+var _ driver.RowsNextResultSet = (*rows)(nil)
+
+func (r *rows) Columns() []string {
+	return r.sets[r.set].columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	set := r.sets[r.set]
+	if r.pos >= len(set.rows) {
+		return io.EOF
+	}
+	if err, ok := set.rowErrors[r.pos]; ok {
+		return err
+	}
+
+	copy(dest, set.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+func (r *rows) HasNextResultSet() bool {
+	return r.set+1 < len(r.sets)
+}
+
+func (r *rows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+	r.set++
+	r.pos = 0
+	return nil
+}
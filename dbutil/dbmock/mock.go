@@ -0,0 +1,501 @@
+package dbmock
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrUnexpected 没有匹配的预期
+var ErrUnexpected = errors.New("dbmock: unexpected call")
+
+// Call 为一次执行的记录
+type Call struct {
+	// Kind 为 query、exec、begin、commit、rollback
+	Kind  string
+	Query string
+	Args  []driver.Value
+}
+
+// QueryMatcher 比较预期的语句与实际执行的语句，不匹配时返回错误
+type QueryMatcher func(expected, actual string) error
+
+// QueryMatcherRegexp 按正则匹配，默认的匹配方式
+func QueryMatcherRegexp(expected, actual string) error {
+	re, err := regexp.Compile(expected)
+	if err != nil {
+		return err
+	}
+	if !re.MatchString(actual) {
+		return fmt.Errorf("query %q does not match regexp %q", actual, expected)
+	}
+	return nil
+}
+
+// QueryMatcherEqual 忽略多余空白后完全相等
+func QueryMatcherEqual(expected, actual string) error {
+	if collapseSpaces(expected) != actual {
+		return fmt.Errorf("query %q does not equal %q", actual, expected)
+	}
+	return nil
+}
+
+var spacesRe = regexp.MustCompile(`\s+`)
+
+func collapseSpaces(s string) string {
+	return strings.TrimSpace(spacesRe.ReplaceAllString(s, " "))
+}
+
+// Mock 记录执行的语句及参数，并按预期返回脚本中的结果。
+// 所有方法都是并发安全的。
+type Mock struct {
+	mu           sync.Mutex
+	expectations []expectation
+	calls        []Call
+	ordered      bool
+	matcher      QueryMatcher
+	close        func()
+}
+
+func newMock() *Mock {
+	return &Mock{ordered: true, matcher: QueryMatcherRegexp}
+}
+
+// MatchExpectationsInOrder 设置预期是否须按顺序满足，默认为 true
+func (m *Mock) MatchExpectationsInOrder(ordered bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ordered = ordered
+}
+
+// SetQueryMatcher 设置语句的匹配方式，默认为 QueryMatcherRegexp
+func (m *Mock) SetQueryMatcher(matcher QueryMatcher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.matcher = matcher
+}
+
+// Calls 返回所有执行的记录
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// Close 释放 Mock 注册的 dsn，*sql.DB 关闭后调用
+func (m *Mock) Close() {
+	if m.close != nil {
+		m.close()
+	}
+}
+
+// ExpectationsWereMet 所有预期都已满足时返回 nil
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pending []string
+	for _, e := range m.expectations {
+		if !e.base().triggered {
+			pending = append(pending, e.String())
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("dbmock: there are unfulfilled expectations:\n  %s", strings.Join(pending, "\n  "))
+	}
+	return nil
+}
+
+// ExpectQuery 预期执行查询，query 由 QueryMatcher 匹配
+func (m *Mock) ExpectQuery(query string) *ExpectedQuery {
+	e := &ExpectedQuery{expectedSQL: expectedSQL{query: query}}
+	m.expect(e)
+	return e
+}
+
+// ExpectExec 预期执行语句，query 由 QueryMatcher 匹配
+func (m *Mock) ExpectExec(query string) *ExpectedExec {
+	e := &ExpectedExec{expectedSQL: expectedSQL{query: query}}
+	m.expect(e)
+	return e
+}
+
+// ExpectBegin 预期开启事务
+func (m *Mock) ExpectBegin() *ExpectedTx {
+	e := &ExpectedTx{kind: "begin"}
+	m.expect(e)
+	return e
+}
+
+// ExpectCommit 预期提交事务
+func (m *Mock) ExpectCommit() *ExpectedTx {
+	e := &ExpectedTx{kind: "commit"}
+	m.expect(e)
+	return e
+}
+
+// ExpectRollback 预期回滚事务
+func (m *Mock) ExpectRollback() *ExpectedTx {
+	e := &ExpectedTx{kind: "rollback"}
+	m.expect(e)
+	return e
+}
+
+func (m *Mock) expect(e expectation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expectations = append(m.expectations, e)
+}
+
+// match 查找匹配的预期并标记为已触发，调用方须持有锁
+func (m *Mock) match(call Call) (expectation, error) {
+	var mismatch error
+	for _, e := range m.expectations {
+		b := e.base()
+		if b.triggered {
+			continue
+		}
+
+		err := e.match(m, call)
+		if err == nil {
+			b.triggered = true
+			return e, nil
+		}
+		if m.ordered {
+			return nil, fmt.Errorf("%w: %s %q with args %v, next expectation is %s: %v",
+				ErrUnexpected, call.Kind, call.Query, call.Args, e, err)
+		}
+		if mismatch == nil {
+			mismatch = err
+		}
+	}
+
+	if mismatch != nil {
+		return nil, fmt.Errorf("%w: %s %q with args %v: %v", ErrUnexpected, call.Kind, call.Query, call.Args, mismatch)
+	}
+	return nil, fmt.Errorf("%w: %s %q with args %v, all expectations were already fulfilled",
+		ErrUnexpected, call.Kind, call.Query, call.Args)
+}
+
+func (m *Mock) record(call Call) (expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, call)
+	return m.match(call)
+}
+
+func (m *Mock) query(ctx context.Context, query string, args []driver.Value) (driver.Rows, error) {
+	e, err := m.record(Call{Kind: "query", Query: collapseSpaces(query), Args: args})
+	if err != nil {
+		return nil, err
+	}
+
+	q := e.(*ExpectedQuery)
+	if err = q.wait(ctx); err != nil {
+		return nil, err
+	}
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	sets := q.rows
+	if len(sets) == 0 {
+		sets = []*Rows{NewRows()}
+	}
+	return &rows{sets: sets}, nil
+}
+
+func (m *Mock) exec(ctx context.Context, query string, args []driver.Value) (driver.Result, error) {
+	e, err := m.record(Call{Kind: "exec", Query: collapseSpaces(query), Args: args})
+	if err != nil {
+		return nil, err
+	}
+
+	x := e.(*ExpectedExec)
+	if err = x.wait(ctx); err != nil {
+		return nil, err
+	}
+	if x.err != nil {
+		return nil, x.err
+	}
+	if x.result == nil {
+		return NewResult(0, 0), nil
+	}
+	return x.result, nil
+}
+
+func (m *Mock) begin() error {
+	return m.txCall("begin")
+}
+
+func (m *Mock) commit() error {
+	return m.txCall("commit")
+}
+
+func (m *Mock) rollback() error {
+	return m.txCall("rollback")
+}
+
+func (m *Mock) txCall(kind string) error {
+	e, err := m.record(Call{Kind: kind})
+	if err != nil {
+		return err
+	}
+	return e.(*ExpectedTx).err
+}
+
+type expectation interface {
+	fmt.Stringer
+	base() *expectationBase
+	match(m *Mock, call Call) error
+}
+
+type expectationBase struct {
+	triggered bool
+}
+
+func (b *expectationBase) base() *expectationBase {
+	return b
+}
+
+type expectedSQL struct {
+	expectationBase
+	query string
+	args  []interface{}
+	// withArgs 为 false 时不比较参数
+	withArgs bool
+	err      error
+	delay    <-chan struct{}
+}
+
+func (e *expectedSQL) matchSQL(m *Mock, kind string, call Call) error {
+	if call.Kind != kind {
+		return fmt.Errorf("expected %s, but got %s", kind, call.Kind)
+	}
+	if err := m.matcher(e.query, call.Query); err != nil {
+		return err
+	}
+	if !e.withArgs {
+		return nil
+	}
+	return matchArgs(e.args, call.Args)
+}
+
+// wait 等待 WillBlock 的通道关闭或 ctx 取消
+func (e *expectedSQL) wait(ctx context.Context) error {
+	if e.delay == nil {
+		return nil
+	}
+	select {
+	case <-e.delay:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ExpectedQuery 为预期的查询
+type ExpectedQuery struct {
+	expectedSQL
+	rows []*Rows
+}
+
+// WithArgs 预期的参数，参数可以为 Argument，如 AnyArg()
+func (e *ExpectedQuery) WithArgs(args ...interface{}) *ExpectedQuery {
+	e.args, e.withArgs = args, true
+	return e
+}
+
+// WillReturnRows 返回的结果集，多个时依次为各结果集
+func (e *ExpectedQuery) WillReturnRows(rows ...*Rows) *ExpectedQuery {
+	e.rows = rows
+	return e
+}
+
+// WillReturnError 返回错误
+func (e *ExpectedQuery) WillReturnError(err error) *ExpectedQuery {
+	e.err = err
+	return e
+}
+
+// WillBlock 阻塞至 ch 关闭或 context 取消，用于测试超时
+func (e *ExpectedQuery) WillBlock(ch <-chan struct{}) *ExpectedQuery {
+	e.delay = ch
+	return e
+}
+
+func (e *ExpectedQuery) match(m *Mock, call Call) error {
+	return e.matchSQL(m, "query", call)
+}
+
+func (e *ExpectedQuery) String() string {
+	return fmt.Sprintf("query %q with args %v", e.query, e.args)
+}
+
+// ExpectedExec 为预期的语句执行
+type ExpectedExec struct {
+	expectedSQL
+	result driver.Result
+}
+
+// WithArgs 预期的参数，参数可以为 Argument，如 AnyArg()
+func (e *ExpectedExec) WithArgs(args ...interface{}) *ExpectedExec {
+	e.args, e.withArgs = args, true
+	return e
+}
+
+// WillReturnResult 返回的结果，可使用 NewResult 创建
+func (e *ExpectedExec) WillReturnResult(result driver.Result) *ExpectedExec {
+	e.result = result
+	return e
+}
+
+// WillReturnError 返回错误
+func (e *ExpectedExec) WillReturnError(err error) *ExpectedExec {
+	e.err = err
+	return e
+}
+
+// WillBlock 阻塞至 ch 关闭或 context 取消，用于测试超时
+func (e *ExpectedExec) WillBlock(ch <-chan struct{}) *ExpectedExec {
+	e.delay = ch
+	return e
+}
+
+func (e *ExpectedExec) match(m *Mock, call Call) error {
+	return e.matchSQL(m, "exec", call)
+}
+
+func (e *ExpectedExec) String() string {
+	return fmt.Sprintf("exec %q with args %v", e.query, e.args)
+}
+
+// ExpectedTx 为预期的事务操作
+type ExpectedTx struct {
+	expectationBase
+	kind string
+	err  error
+}
+
+// WillReturnError 返回错误
+func (e *ExpectedTx) WillReturnError(err error) *ExpectedTx {
+	e.err = err
+	return e
+}
+
+func (e *ExpectedTx) match(m *Mock, call Call) error {
+	if call.Kind != e.kind {
+		return fmt.Errorf("expected %s, but got %s", e.kind, call.Kind)
+	}
+	return nil
+}
+
+func (e *ExpectedTx) String() string {
+	return e.kind
+}
+
+// Argument 自定义参数匹配
+type Argument interface {
+	Match(driver.Value) bool
+}
+
+type anyArg struct{}
+
+func (anyArg) Match(driver.Value) bool {
+	return true
+}
+
+func (anyArg) String() string {
+	return "<any>"
+}
+
+// AnyArg 匹配任意参数
+func AnyArg() Argument {
+	return anyArg{}
+}
+
+func matchArgs(expected []interface{}, actual []driver.Value) error {
+	if len(expected) != len(actual) {
+		return fmt.Errorf("expected %d args, but got %d", len(expected), len(actual))
+	}
+
+	for i, e := range expected {
+		if arg, ok := e.(Argument); ok {
+			if !arg.Match(actual[i]) {
+				return fmt.Errorf("arg #%d %v does not match %v", i, actual[i], e)
+			}
+			continue
+		}
+
+		// 与 database/sql 一致地转换预期参数，如 int 转为 int64
+		v, err := driver.DefaultParameterConverter.ConvertValue(e)
+		if err != nil {
+			return fmt.Errorf("arg #%d: %w", i, err)
+		}
+		if !reflect.DeepEqual(v, actual[i]) {
+			return fmt.Errorf("arg #%d is %v(%T), but expected %v(%T)", i, actual[i], actual[i], v, v)
+		}
+	}
+	return nil
+}
+
+// Rows 为脚本中的结果集
+type Rows struct {
+	columns   []string
+	rows      [][]driver.Value
+	rowErrors map[int]error
+}
+
+// NewRows 创建结果集
+func NewRows(columns ...string) *Rows {
+	return &Rows{columns: columns, rowErrors: map[int]error{}}
+}
+
+// AddRow 添加一行，值的个数须与列数相同。
+// 值与真实驱动一致地转换为 driver.Value，如 int 转为 int64，无法转换时 panic。
+func (r *Rows) AddRow(values ...interface{}) *Rows {
+	if len(values) != len(r.columns) {
+		panic(fmt.Sprintf("dbmock: expected %d values, but got %d", len(r.columns), len(values)))
+	}
+
+	row := make([]driver.Value, len(values))
+	for i, v := range values {
+		value, err := driver.DefaultParameterConverter.ConvertValue(v)
+		if err != nil {
+			panic(fmt.Sprintf("dbmock: column %s: %v", r.columns[i], err))
+		}
+		row[i] = value
+	}
+	r.rows = append(r.rows, row)
+	return r
+}
+
+// RowError 读取第 row 行时返回错误，row 从 0 开始
+func (r *Rows) RowError(row int, err error) *Rows {
+	r.rowErrors[row] = err
+	return r
+}
+
+type result struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+// NewResult 创建语句执行的结果
+func NewResult(lastInsertID, rowsAffected int64) driver.Result {
+	return result{lastInsertID: lastInsertID, rowsAffected: rowsAffected}
+}
+
+func (r result) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r result) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
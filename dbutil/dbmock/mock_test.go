@@ -0,0 +1,111 @@
+package dbmock
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/joker-circus/gotools"
+	"github.com/joker-circus/gotools/dbutil/insert"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockQuery(t *testing.T) {
+	db, mock := New()
+	defer mock.Close()
+	defer db.Close()
+
+	mock.ExpectQuery(`select id, name from users where age >= \?`).WithArgs(18).
+		WillReturnRows(NewRows("id", "name").AddRow(int64(1), "joker").AddRow(int64(2), "circus"))
+	mock.ExpectQuery("from users").WithArgs(AnyArg()).
+		WillReturnRows(NewRows("id").AddRow(int64(1)), NewRows("name").AddRow("joker"))
+
+	columns, rows, err := gotools.Query(db, "select id, name\n  from users where age >= ?", 18)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id", "name"}, columns)
+	assert.Equal(t, [][]interface{}{{int64(1), "joker"}, {int64(2), "circus"}}, rows)
+
+	var got []interface{}
+	err = gotools.Each(context.Background(), db, "select * from users where id = ?", []interface{}{1}, func(row gotools.EachRow) error {
+		got = append(got, row.Values[0])
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{int64(1), "joker"}, got)
+
+	assert.Nil(t, mock.ExpectationsWereMet())
+	assert.Equal(t, "select id, name from users where age >= ?", mock.Calls()[0].Query)
+}
+
+func TestRowsAddRow(t *testing.T) {
+	db, mock := New()
+	defer mock.Close()
+	defer db.Close()
+
+	mock.ExpectQuery("select").WillReturnRows(NewRows("id", "score", "ok").AddRow(1, float32(1.5), true))
+	var id, score, ok interface{}
+	assert.Nil(t, db.QueryRow("select id, score, ok from users").Scan(&id, &score, &ok))
+	assert.Equal(t, int64(1), id)
+	assert.Equal(t, float64(1.5), score)
+	assert.Equal(t, true, ok)
+
+	assert.PanicsWithValue(t, "dbmock: column tags: unsupported type []string, a slice of string", func() {
+		NewRows("id", "tags").AddRow(1, []string{"a"})
+	})
+}
+
+func TestMockUnexpected(t *testing.T) {
+	db, mock := New()
+	defer mock.Close()
+	defer db.Close()
+
+	mock.ExpectExec("delete from users").WithArgs(1)
+	mock.ExpectQuery("select")
+
+	_, err := db.Exec("delete from users where id = ?", 2)
+	assert.ErrorIs(t, err, ErrUnexpected)
+
+	_, _, err = gotools.QueryMap(db, "select 1")
+	assert.ErrorIs(t, err, ErrUnexpected)
+	assert.NotNil(t, mock.ExpectationsWereMet())
+
+	mock.MatchExpectationsInOrder(false)
+	_, _, err = gotools.QueryMap(db, "select 1")
+	assert.Nil(t, err)
+
+	errBoom := errors.New("boom")
+	mock.ExpectExec("update").WillReturnError(errBoom)
+	_, err = db.Exec("update users set name = ''")
+	assert.ErrorIs(t, err, errBoom)
+}
+
+type mockUser struct {
+	ID   int64
+	Name string
+}
+
+func TestMockInsertBatches(t *testing.T) {
+	db, mock := New()
+	defer mock.Close()
+	defer db.Close()
+
+	users := []mockUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}}
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `mock_users`").WithArgs(int64(1), "a", int64(2), "b").
+		WillReturnResult(NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO `mock_users`").WithArgs(int64(3), "c").
+		WillReturnResult(NewResult(0, 1))
+	mock.ExpectCommit()
+
+	affected, err := insert.ExecBatches(context.Background(), db, users, insert.BatchOptions{MaxRows: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{2, 1}, affected)
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT").WillReturnError(errors.New("duplicate"))
+	mock.ExpectRollback()
+	_, err = insert.ExecBatches(context.Background(), db, users, insert.BatchOptions{})
+	assert.NotNil(t, err)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/joker-circus/gotools/dbutil/insert"
+)
+
+// Dialect defines the placeholders and the identifier quotes of a database.
+type Dialect = insert.Dialect
+
+const (
+	MySQL      = insert.MySQL
+	PostgreSQL = insert.PostgreSQL
+	SQLite     = insert.SQLite
+	Oracle     = insert.Oracle
+)
+
+var identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// quoteIdentifier quotes s only if it is a plain identifier like "name" or
+// "user.name", expressions like "count(*)" are kept as they are.
+// Quoted names are case-sensitive on PostgreSQL and Oracle, which fold the
+// unquoted names to lower and upper case, so a part is quoted only if it is
+// already in the folded case, e.g. "name" is kept as it is on Oracle and
+// still resolves to the NAME column.
+func quoteIdentifier(d Dialect, s string) string {
+	if !identifierRe.MatchString(s) {
+		return s
+	}
+
+	parts := strings.Split(s, ".")
+	for i, part := range parts {
+		if isFoldedCase(d, part) {
+			parts[i] = d.Quote(part)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// isFoldedCase reports whether quoting the name keeps the column it resolves to.
+func isFoldedCase(d Dialect, name string) bool {
+	switch d {
+	case PostgreSQL:
+		return strings.ToLower(name) == name
+	case Oracle:
+		return strings.ToUpper(name) == name
+	}
+	return true
+}
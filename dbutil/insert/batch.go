@@ -0,0 +1,192 @@
+package insert
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/joker-circus/gotools"
+)
+
+// BatchOptions 定义批量写入的拆分条件，超出任一条件时拆分为新的语句。
+type BatchOptions struct {
+	// Dialect 决定标识符引号及占位符，默认 MySQL
+	Dialect Dialect
+	// OnConflict 不为空时生成 upsert 语句，默认值同 BuildUpsertSQL
+	OnConflict *OnConflict
+
+	// MaxRows 每条语句的最大行数，0 为不限制
+	MaxRows int
+	// MaxPlaceholders 每条语句的最大占位符数，0 为方言的上限，
+	// SQLite 3.32 之前的版本需设置为 999
+	MaxPlaceholders int
+	// MaxBytes 每条语句及参数的估算字节数上限，0 为不限制，
+	// 用于 MySQL max_allowed_packet 等限制，建议预留一定余量
+	MaxBytes int
+}
+
+// Batch 为拆分后的一条预编译语句
+type Batch struct {
+	SQL    string
+	Values []interface{}
+	Rows   int
+}
+
+// MaxPlaceholders 返回方言单条语句允许的最大占位符数，
+// MySQL、PostgreSQL 为 65535，SQLite 为 32766（3.32 之前为 999）
+func (d Dialect) MaxPlaceholders() int {
+	switch d {
+	case SQLite:
+		return 32766
+	default:
+		return 65535
+	}
+}
+
+// BuildBatches 生成批量创建语句，按行数、占位符数、字节数拆分为多条语句。
+func BuildBatches(dest interface{}, opt BatchOptions) ([]Batch, error) {
+	schema, sqlBuild, err := buildSchemaSQL(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlBuild.Dialect = opt.Dialect
+	if opt.OnConflict != nil {
+		sqlBuild.OnConflict = opt.OnConflict.resolve(schema)
+	}
+
+	maxPlaceholders := opt.MaxPlaceholders
+	if maxPlaceholders <= 0 {
+		maxPlaceholders = opt.Dialect.MaxPlaceholders()
+	}
+	columns := len(sqlBuild.Columns)
+	if columns > maxPlaceholders {
+		return nil, fmt.Errorf("%d columns exceed the placeholder limit %d", columns, maxPlaceholders)
+	}
+
+	// 语句中除 VALUES 各行外的字节数
+	baseBytes := 0
+	if opt.MaxBytes > 0 {
+		head := sqlBuild
+		head.Values = nil
+		preSQL, err := head.PreSQL()
+		if err != nil {
+			return nil, err
+		}
+		baseBytes = len(preSQL)
+	}
+
+	var (
+		batches []Batch
+		start   int
+		bytes   = baseBytes
+	)
+	flush := func(end int) error {
+		chunk := sqlBuild
+		chunk.Values = sqlBuild.Values[start:end]
+		preSQL, values, err := chunk.preSQLWithValues()
+		if err != nil {
+			return err
+		}
+		batches = append(batches, Batch{SQL: preSQL, Values: values, Rows: end - start})
+		start, bytes = end, baseBytes
+		return nil
+	}
+
+	for i, row := range sqlBuild.Values {
+		rowBytes := 0
+		if opt.MaxBytes > 0 {
+			rowBytes = estimateRowBytes(row)
+		}
+
+		rows := i - start
+		if rows > 0 && ((opt.MaxRows > 0 && rows >= opt.MaxRows) ||
+			(rows+1)*columns > maxPlaceholders ||
+			(opt.MaxBytes > 0 && bytes+rowBytes > opt.MaxBytes)) {
+			if err := flush(i); err != nil {
+				return nil, err
+			}
+		}
+		bytes += rowBytes
+	}
+	if err := flush(len(sqlBuild.Values)); err != nil {
+		return nil, err
+	}
+
+	return batches, nil
+}
+
+// estimateRowBytes 估算一行的占位符及参数字节数
+func estimateRowBytes(row []interface{}) int {
+	// "(" + ")," 及每个占位符、逗号，$n 形式的占位符按 6 字节估算
+	n := 3 + 7*len(row)
+	for _, v := range row {
+		switch vv := v.(type) {
+		case string:
+			n += len(vv)
+		case []byte:
+			n += len(vv)
+		case nil:
+			n++
+		default:
+			rv := reflect.Indirect(reflect.ValueOf(v))
+			if rv.Kind() == reflect.String {
+				n += rv.Len()
+			} else {
+				n += 8
+			}
+		}
+	}
+	return n
+}
+
+// ExecBatches 在一个事务中执行拆分后的批量创建语句，返回各批次影响的行数。
+// 任一批次失败时回滚事务，返回已执行批次的影响行数及错误。
+func ExecBatches(ctx context.Context, db gotools.DB, dest interface{}, opt BatchOptions) ([]int64, error) {
+	batches, err := BuildBatches(dest, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := execBatches(ctx, tx, batches)
+	if err != nil {
+		tx.Rollback()
+		return affected, err
+	}
+
+	return affected, tx.Commit()
+}
+
+// ExecBatchesTx 在已有事务中执行拆分后的批量创建语句，返回各批次影响的行数，
+// 事务的提交及回滚由调用方负责。
+func ExecBatchesTx(ctx context.Context, tx gotools.Tx, dest interface{}, opt BatchOptions) ([]int64, error) {
+	batches, err := BuildBatches(dest, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return execBatches(ctx, tx, batches)
+}
+
+func execBatches(ctx context.Context, stmt gotools.Stmt, batches []Batch) ([]int64, error) {
+	affected := make([]int64, 0, len(batches))
+	for i, batch := range batches {
+		result, err := stmt.ExecContext(ctx, batch.SQL, batch.Values...)
+		if err != nil {
+			return affected, fmt.Errorf("batch #%d: %w", i, err)
+		}
+
+		n, err := result.RowsAffected()
+		if err != nil {
+			return affected, fmt.Errorf("batch #%d: %w", i, err)
+		}
+		affected = append(affected, n)
+	}
+
+	return affected, nil
+}
@@ -0,0 +1,32 @@
+package insert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildBatches(t *testing.T) {
+	users := make([]upsertUser, 10)
+	for i := range users {
+		users[i] = upsertUser{ID: int64(i + 1), Email: "x", Name: "name"}
+	}
+
+	batches, err := BuildBatches(users, BatchOptions{MaxRows: 4})
+	assert.Nil(t, err)
+	assert.Len(t, batches, 3)
+	assert.Equal(t, []int{4, 4, 2}, []int{batches[0].Rows, batches[1].Rows, batches[2].Rows})
+	assert.Len(t, batches[2].Values, 8)
+
+	batches, err = BuildBatches(users, BatchOptions{Dialect: PostgreSQL, MaxPlaceholders: 12})
+	assert.Nil(t, err)
+	assert.Len(t, batches, 4)
+	assert.Equal(t, `INSERT INTO "upsert_users" ("id","email","name","age") VALUES ($1,$2,$3,$4),($5,$6,$7,$8),($9,$10,$11,$12)`, batches[0].SQL)
+
+	batches, err = BuildBatches(users, BatchOptions{MaxBytes: 1})
+	assert.Nil(t, err)
+	assert.Len(t, batches, 10)
+
+	_, err = BuildBatches(users, BatchOptions{MaxPlaceholders: 3})
+	assert.NotNil(t, err)
+}
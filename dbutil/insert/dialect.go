@@ -0,0 +1,77 @@
+package insert
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Dialect defines the placeholders and the identifier quotes of a database.
+type Dialect int
+
+const (
+	MySQL Dialect = iota
+	PostgreSQL
+	SQLite
+	Oracle
+)
+
+var dialectNames = []string{
+	"mysql",
+	"postgres",
+	"sqlite",
+	"oracle",
+}
+
+func (d Dialect) String() string {
+	if d >= MySQL && int(d) < len(dialectNames) {
+		return dialectNames[d]
+	}
+	return "dialect(" + strconv.Itoa(int(d)) + ")"
+}
+
+// Placeholder returns the placeholder of the n-th argument, n starts from 1.
+// e.g. "?" for MySQL and SQLite, "$1" for PostgreSQL, ":1" for Oracle.
+func (d Dialect) Placeholder(n int) string {
+	switch d {
+	case PostgreSQL:
+		return "$" + strconv.Itoa(n)
+	case Oracle:
+		return ":" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
+
+// Quote quotes the identifier, e.g. `user`.`name` for MySQL and
+// "user"."name" for the others. Dotted identifiers are quoted part by part.
+func (d Dialect) Quote(ident string) string {
+	q := `"`
+	if d == MySQL {
+		q = "`"
+	}
+
+	parts := strings.Split(ident, ".")
+	for i, part := range parts {
+		parts[i] = q + strings.ReplaceAll(part, q, q+q) + q
+	}
+	return strings.Join(parts, ".")
+}
+
+var (
+	dollarPlaceholderRe = regexp.MustCompile(`\$(\d+)`)
+	colonPlaceholderRe  = regexp.MustCompile(`:(\d+)`)
+)
+
+// numericPlaceholder returns the regexp of the numbered placeholders
+// used by ExplainSQL, nil for "?".
+func (d Dialect) numericPlaceholder() *regexp.Regexp {
+	switch d {
+	case PostgreSQL:
+		return dollarPlaceholderRe
+	case Oracle:
+		return colonPlaceholderRe
+	default:
+		return nil
+	}
+}
@@ -39,9 +39,14 @@ func BuildPreSQL(dest interface{}) (sql string, values []interface{}, err error)
 }
 
 func buildSQL(dest interface{}) (SQL, error) {
+	_, sqlBuild, err := buildSchemaSQL(dest)
+	return sqlBuild, err
+}
+
+func buildSchemaSQL(dest interface{}) (*Schema, SQL, error) {
 	schema, err := GetSchema(dest)
 	if err != nil {
-		return SQL{}, err
+		return nil, SQL{}, err
 	}
 
 	now := reflect.ValueOf(time.Now())
@@ -60,10 +65,11 @@ func buildSQL(dest interface{}) (SQL, error) {
 		reflectValue = reflectValue.Elem()
 	}
 	if !reflectValue.IsValid() {
-		return SQL{}, ErrInvalidValue
+		return nil, SQL{}, ErrInvalidValue
 	}
 
-	return initSQL(schema, reflectValue, defaultValue)
+	sqlBuild, err := initSQL(schema, reflectValue, defaultValue)
+	return schema, sqlBuild, err
 }
 
 func initSQL(schema *Schema, reflectValue reflect.Value, defaultValue map[string]reflect.Value) (SQL, error) {
@@ -102,6 +108,16 @@ func initSQL(schema *Schema, reflectValue reflect.Value, defaultValue map[string
 
 // 获取结构体的所有值
 func getStructValues(reflectValue reflect.Value, schema *Schema, defaultValue map[string]reflect.Value) []interface{} {
+	values := getStructValueMap(reflectValue, schema, defaultValue)
+	data := make([]interface{}, len(schema.DBNames))
+	for idx, column := range schema.DBNames {
+		data[idx] = values[column]
+	}
+	return data
+}
+
+// 获取结构体的所有值，key 为列名
+func getStructValueMap(reflectValue reflect.Value, schema *Schema, defaultValue map[string]reflect.Value) map[string]interface{} {
 	values := make(map[string]interface{})
 	r := &internal.StructX{T: reflectValue.Type(), V: reflectValue}
 	r.RangeFields(false, func(sf reflect.StructField, rv reflect.Value) bool {
@@ -130,9 +146,5 @@ func getStructValues(reflectValue reflect.Value, schema *Schema, defaultValue ma
 		return true
 	})
 
-	data := make([]interface{}, len(schema.DBNames))
-	for idx, column := range schema.DBNames {
-		data[idx] = values[column]
-	}
-	return data
+	return values
 }
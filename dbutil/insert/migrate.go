@@ -0,0 +1,484 @@
+package insert
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joker-circus/gotools"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	valuerType   = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	bytesType    = reflect.TypeOf([]byte(nil))
+	gormDataType = reflect.TypeOf((*GormDataTypeInterface)(nil)).Elem()
+	intWidthRe   = regexp.MustCompile(`^(tinyint|smallint|mediumint|int|integer|bigint)\(\d+\)`)
+	spacesRe     = regexp.MustCompile(`\s+`)
+	pgTypeAlias  = map[string]string{
+		"character varying":        "varchar",
+		"timestamp with time zone": "timestamptz",
+		"double precision":         "float8",
+		"bigserial":                "bigint",
+		"serial":                   "integer",
+		"smallserial":              "smallint",
+		"int":                      "integer",
+		"real":                     "float4",
+	}
+)
+
+// ColumnInfo 为数据库中已有的列，Default 为 nil 时没有默认值
+type ColumnInfo struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  *string
+}
+
+// DataType 返回字段在方言中的列类型，优先使用 gorm type 标签，
+// string 的长度取自 size 标签。
+func (field *Field) DataType(dialect Dialect) string {
+	if t := field.TagSettings["TYPE"]; t != "" {
+		return t
+	}
+
+	ft := field.FieldType
+	for ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+
+	dataType := ""
+	if reflect.PtrTo(ft).Implements(gormDataType) {
+		dataType = reflect.New(ft).Interface().(GormDataTypeInterface).GormDataType()
+	}
+	size, _ := strconv.Atoi(field.TagSettings["SIZE"])
+
+	switch {
+	case dataType == "time" || ft.ConvertibleTo(timeType):
+		return dialectType(dialect, "datetime(3)", "timestamptz", "datetime")
+	case dataType == "bytes" || ft == bytesType:
+		return dialectType(dialect, "longblob", "bytea", "blob")
+	case dataType == "json":
+		return dialectType(dialect, "json", "jsonb", "text")
+	}
+
+	switch ft.Kind() {
+	case reflect.Bool:
+		return dialectType(dialect, "boolean", "boolean", "numeric")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field.intType(dialect, ft)
+	case reflect.Float32:
+		return dialectType(dialect, "float", "real", "real")
+	case reflect.Float64:
+		return dialectType(dialect, "double", "double precision", "real")
+	case reflect.String:
+		if size > 0 {
+			return dialectType(dialect, fmt.Sprintf("varchar(%d)", size), fmt.Sprintf("varchar(%d)", size), "text")
+		}
+		// MySQL 的索引列不支持 longtext
+		if field.PrimaryKey || field.indexed() {
+			return dialectType(dialect, "varchar(191)", "text", "text")
+		}
+		return dialectType(dialect, "longtext", "text", "text")
+	}
+
+	// 实现 driver.Valuer 的类型，如 DBJsonField、DBStringSlice，按文本存储
+	if ft.Implements(valuerType) || reflect.PtrTo(ft).Implements(valuerType) {
+		return dialectType(dialect, "longtext", "text", "text")
+	}
+	return ""
+}
+
+func (field *Field) intType(dialect Dialect, ft reflect.Type) string {
+	unsigned := ft.Kind() >= reflect.Uint && ft.Kind() <= reflect.Uint64
+	if field.autoIncrement() {
+		if unsigned {
+			return dialectType(dialect, "bigint unsigned AUTO_INCREMENT", "bigserial", "integer")
+		}
+		return dialectType(dialect, "bigint AUTO_INCREMENT", "bigserial", "integer")
+	}
+
+	bits := ft.Bits()
+	switch dialect {
+	case MySQL:
+		t := "bigint"
+		switch {
+		case bits <= 8:
+			t = "tinyint"
+		case bits <= 16:
+			t = "smallint"
+		case bits <= 32:
+			t = "int"
+		}
+		if unsigned {
+			t += " unsigned"
+		}
+		return t
+	case PostgreSQL:
+		// PostgreSQL 没有无符号整数，使用更大的类型
+		if unsigned {
+			bits *= 2
+		}
+		switch {
+		case bits <= 16:
+			return "smallint"
+		case bits <= 32:
+			return "integer"
+		default:
+			return "bigint"
+		}
+	default:
+		return "integer"
+	}
+}
+
+// autoIncrement 整数主键默认自增，与 gorm 一致，autoIncrement:false 时关闭
+func (field *Field) autoIncrement() bool {
+	if v, ok := field.TagSettings["AUTOINCREMENT"]; ok {
+		return isTrue(v)
+	}
+	return field.PrimaryKey && len(field.Schema.PrimaryFields) <= 1
+}
+
+func (field *Field) indexed() bool {
+	_, index := field.TagSettings["INDEX"]
+	_, uniqueIndex := field.TagSettings["UNIQUEINDEX"]
+	return index || uniqueIndex || isTrue(field.TagSettings["UNIQUE"])
+}
+
+// columnDefinition 返回列定义，如 `age` int NOT NULL DEFAULT 0
+func (field *Field) columnDefinition(dialect Dialect) string {
+	return field.definition(dialect, field.notNull())
+}
+
+// addColumnDefinition 返回 ADD COLUMN 的列定义，没有默认值的列不加 NOT NULL，
+// 否则非空表添加列会失败，SQLite 则总是失败
+func (field *Field) addColumnDefinition(dialect Dialect) string {
+	_, hasDefault := field.TagSettings["DEFAULT"]
+	return field.definition(dialect, field.notNull() && hasDefault)
+}
+
+func (field *Field) definition(dialect Dialect, notNull bool) string {
+	var b strings.Builder
+	b.WriteString(dialect.Quote(field.DBName))
+	b.WriteByte(' ')
+	b.WriteString(field.DataType(dialect))
+
+	if dialect == SQLite && field.PrimaryKey && field.autoIncrement() {
+		b.WriteString(" PRIMARY KEY AUTOINCREMENT")
+		return b.String()
+	}
+	if notNull {
+		b.WriteString(" NOT NULL")
+	}
+	if v, ok := field.defaultValue(); ok {
+		b.WriteString(" DEFAULT ")
+		b.WriteString(v)
+	}
+	return b.String()
+}
+
+func (field *Field) notNull() bool {
+	return field.PrimaryKey || isTrue(field.TagSettings["NOT NULL"])
+}
+
+func (field *Field) defaultValue() (string, bool) {
+	v, ok := field.TagSettings["DEFAULT"]
+	return strings.TrimSpace(v), ok
+}
+
+func dialectType(dialect Dialect, mysql, postgres, sqlite string) string {
+	switch dialect {
+	case PostgreSQL:
+		return postgres
+	case SQLite:
+		return sqlite
+	default:
+		return mysql
+	}
+}
+
+// 生成建表语句，PostgreSQL、SQLite 的索引为单独的 CREATE INDEX 语句。
+func BuildCreateTableSQL(dialect Dialect, dest interface{}) ([]string, error) {
+	schema, err := GetSchema(dest)
+	if err != nil {
+		return nil, err
+	}
+	return buildCreateTableSQL(dialect, schema)
+}
+
+func buildCreateTableSQL(dialect Dialect, schema *Schema) ([]string, error) {
+	if dialect != MySQL && dialect != PostgreSQL && dialect != SQLite {
+		return nil, fmt.Errorf("%w: ddl is not supported by %s", ErrUnsupportedDriver, dialect)
+	}
+
+	var definitions []string
+	sqliteAutoIncrement := false
+	for _, field := range schema.Fields {
+		if field.DataType(dialect) == "" {
+			return nil, fmt.Errorf("%w: %s.%s is %s", ErrUnsupportedDataType, schema.Name, field.Name, field.FieldType)
+		}
+		definitions = append(definitions, field.columnDefinition(dialect))
+		if dialect == SQLite && field.PrimaryKey && field.autoIncrement() {
+			sqliteAutoIncrement = true
+		}
+	}
+	if len(schema.PrimaryFieldDBNames) > 0 && !sqliteAutoIncrement {
+		definitions = append(definitions, "PRIMARY KEY ("+quoteColumns(dialect, schema.PrimaryFieldDBNames)+")")
+	}
+
+	var indexSQL []string
+	for _, index := range schema.Indexes() {
+		if dialect == MySQL {
+			kind := "INDEX"
+			if index.Unique {
+				kind = "UNIQUE INDEX"
+			}
+			definitions = append(definitions, fmt.Sprintf("%s %s (%s)", kind, dialect.Quote(index.Name), quoteColumns(dialect, index.Columns)))
+			continue
+		}
+		indexSQL = append(indexSQL, createIndexSQL(dialect, schema.Table, index))
+	}
+
+	stmts := []string{fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", dialect.Quote(schema.Table), strings.Join(definitions, ","))}
+	return append(stmts, indexSQL...), nil
+}
+
+func createIndexSQL(dialect Dialect, table string, index *Index) string {
+	kind := "INDEX"
+	if index.Unique {
+		kind = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s IF NOT EXISTS %s ON %s (%s)", kind, dialect.Quote(index.Name), dialect.Quote(table), quoteColumns(dialect, index.Columns))
+}
+
+func quoteColumns(dialect Dialect, columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = dialect.Quote(column)
+	}
+	return strings.Join(quoted, ",")
+}
+
+// 比较结构体与数据库中已有的列，生成迁移语句：
+// 表不存在（columns 为空）时建表，缺少的列 ADD COLUMN 并创建包含该列的索引，
+// 类型、是否可空或默认值不同的列 MySQL 为 MODIFY COLUMN，PostgreSQL 为 ALTER COLUMN，SQLite 不支持修改列而忽略。
+// 新增的 not null 列没有 default 时不加 NOT NULL，多余的列不会被删除，已有列上缺少的索引不会创建。
+func BuildMigrateSQL(dialect Dialect, dest interface{}, columns []ColumnInfo) ([]string, error) {
+	schema, err := GetSchema(dest)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return buildCreateTableSQL(dialect, schema)
+	}
+
+	existing := make(map[string]ColumnInfo, len(columns))
+	for _, column := range columns {
+		existing[strings.ToLower(column.Name)] = column
+	}
+
+	table := dialect.Quote(schema.Table)
+	var stmts []string
+	added := map[string]bool{}
+	for _, field := range schema.Fields {
+		column, ok := existing[strings.ToLower(field.DBName)]
+		if !ok {
+			stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, field.addColumnDefinition(dialect)))
+			added[field.DBName] = true
+			continue
+		}
+		if field.PrimaryKey {
+			continue
+		}
+
+		dataType := field.DataType(dialect)
+		typeChanged := !sameDataType(dialect, column.Type, dataType)
+		nullChanged := column.Nullable == field.notNull()
+		defaultValue, hasDefault := field.defaultValue()
+		defaultChanged := !field.autoIncrement() && !sameDefault(column.Default, defaultValue, hasDefault)
+		switch dialect {
+		case MySQL:
+			if typeChanged || nullChanged || defaultChanged {
+				stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", table, field.columnDefinition(dialect)))
+			}
+		case PostgreSQL:
+			alter := fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s ", table, dialect.Quote(field.DBName))
+			if typeChanged {
+				stmts = append(stmts, alter+"TYPE "+dataType)
+			}
+			if nullChanged && field.notNull() {
+				stmts = append(stmts, alter+"SET NOT NULL")
+			} else if nullChanged {
+				stmts = append(stmts, alter+"DROP NOT NULL")
+			}
+			if defaultChanged && hasDefault {
+				stmts = append(stmts, alter+"SET DEFAULT "+defaultValue)
+			} else if defaultChanged {
+				stmts = append(stmts, alter+"DROP DEFAULT")
+			}
+		}
+	}
+
+	for _, index := range schema.Indexes() {
+		for _, column := range index.Columns {
+			if added[column] {
+				stmts = append(stmts, addIndexSQL(dialect, schema.Table, index))
+				break
+			}
+		}
+	}
+	return stmts, nil
+}
+
+// addIndexSQL 返回给已有的表添加索引的语句，MySQL 不支持 CREATE INDEX IF NOT EXISTS
+func addIndexSQL(dialect Dialect, table string, index *Index) string {
+	if dialect != MySQL {
+		return createIndexSQL(dialect, table, index)
+	}
+	kind := "INDEX"
+	if index.Unique {
+		kind = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD %s %s (%s)", dialect.Quote(table), kind, dialect.Quote(index.Name), quoteColumns(dialect, index.Columns))
+}
+
+// sameDefault 宽松比较默认值，忽略大小写、字符串的引号及 PostgreSQL 的类型转换，如 'a'::character varying
+func sameDefault(actual *string, expected string, hasExpected bool) bool {
+	normalize := func(v string) string {
+		v = strings.TrimSpace(v)
+		if i := strings.LastIndex(v, "::"); i > 0 && !strings.HasSuffix(v, "'") {
+			v = v[:i]
+		}
+		if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+			v = strings.ReplaceAll(v[1:len(v)-1], "''", "'")
+		}
+		if strings.EqualFold(v, "null") {
+			return ""
+		}
+		return strings.ToLower(v)
+	}
+
+	var a string
+	if actual != nil {
+		a = normalize(*actual)
+	}
+	if !hasExpected {
+		return a == ""
+	}
+	return a == normalize(expected)
+}
+
+// sameDataType 宽松比较列类型，忽略大小写、整数显示宽度及类型别名
+func sameDataType(dialect Dialect, actual, expected string) bool {
+	normalize := func(t string) string {
+		t = strings.ToLower(strings.TrimSpace(spacesRe.ReplaceAllString(t, " ")))
+		t = strings.TrimSuffix(t, " auto_increment")
+		t = intWidthRe.ReplaceAllString(t, "$1")
+		if dialect == PostgreSQL {
+			name, size := t, ""
+			if i := strings.IndexByte(t, '('); i >= 0 {
+				name, size = t[:i], t[i:]
+			}
+			if alias, ok := pgTypeAlias[name]; ok {
+				t = alias + size
+			}
+		}
+		if dialect == MySQL && (t == "boolean" || t == "bool") {
+			t = "tinyint"
+		}
+		return t
+	}
+	return normalize(actual) == normalize(expected)
+}
+
+// TableColumns 读取数据库中已有的列，MySQL、PostgreSQL 查询 information_schema，
+// SQLite 查询 PRAGMA table_info，表不存在时返回空。
+func TableColumns(ctx context.Context, db gotools.Stmt, dialect Dialect, table string) ([]ColumnInfo, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	switch dialect {
+	case MySQL:
+		rows, err = db.QueryContext(ctx, "SELECT column_name, column_type, is_nullable, column_default FROM information_schema.columns"+
+			" WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position", table)
+	case PostgreSQL:
+		rows, err = db.QueryContext(ctx, "SELECT column_name, CASE WHEN character_maximum_length IS NULL THEN data_type"+
+			" ELSE data_type || '(' || character_maximum_length || ')' END, is_nullable, column_default FROM information_schema.columns"+
+			" WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position", table)
+	case SQLite:
+		rows, err = db.QueryContext(ctx, "SELECT name, type, \"notnull\" = 0, dflt_value FROM pragma_table_info(?) ORDER BY cid", table)
+	default:
+		return nil, fmt.Errorf("%w: migration is not supported by %s", ErrUnsupportedDriver, dialect)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []ColumnInfo
+	for rows.Next() {
+		var (
+			column   ColumnInfo
+			nullable interface{}
+			def      sql.NullString
+		)
+		if err = rows.Scan(&column.Name, &column.Type, &nullable, &def); err != nil {
+			return nil, err
+		}
+		column.Nullable = isNullable(nullable)
+		if def.Valid {
+			column.Default = &def.String
+		}
+		columns = append(columns, column)
+	}
+	return columns, rows.Err()
+}
+
+func isNullable(v interface{}) bool {
+	switch vv := v.(type) {
+	case []byte:
+		return isNullable(string(vv))
+	case string:
+		return strings.EqualFold(vv, "YES") || vv == "1"
+	case bool:
+		return vv
+	case int64:
+		return vv != 0
+	default:
+		return false
+	}
+}
+
+// Migrate 比较结构体与数据库中的表，执行 BuildMigrateSQL 生成的迁移语句，返回执行的语句。
+func Migrate(ctx context.Context, db gotools.Stmt, dialect Dialect, dest interface{}) ([]string, error) {
+	schema, err := GetSchema(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := TableColumns(ctx, db, dialect, schema.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	stmts, err := BuildMigrateSQL(dialect, dest, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, stmt := range stmts {
+		if _, err = db.ExecContext(ctx, stmt); err != nil {
+			return stmts[:i], fmt.Errorf("%s: %w", stmt, err)
+		}
+	}
+	return stmts, nil
+}
@@ -0,0 +1,120 @@
+package insert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type migrateUser struct {
+	ID        uint64
+	Email     string `gorm:"size:100;uniqueIndex;not null"`
+	Name      string `gorm:"size:50;index:idx_name_age"`
+	Age       int8   `gorm:"index:idx_name_age;default:0"`
+	Score     float64
+	Bio       string
+	Avatar    []byte
+	Remark    string `gorm:"type:varchar(20)"`
+	Enabled   bool
+	CreatedAt time.Time
+	DeletedAt *time.Time
+}
+
+func TestBuildCreateTableSQL(t *testing.T) {
+	stmts, err := BuildCreateTableSQL(MySQL, migrateUser{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"CREATE TABLE IF NOT EXISTS `migrate_users` (`id` bigint unsigned AUTO_INCREMENT NOT NULL," +
+		"`email` varchar(100) NOT NULL,`name` varchar(50),`age` tinyint DEFAULT 0,`score` double,`bio` longtext," +
+		"`avatar` longblob,`remark` varchar(20),`enabled` boolean,`created_at` datetime(3),`deleted_at` datetime(3)," +
+		"PRIMARY KEY (`id`),UNIQUE INDEX `uidx_migrate_users_email` (`email`),INDEX `idx_name_age` (`name`,`age`))"}, stmts)
+
+	stmts, err = BuildCreateTableSQL(PostgreSQL, migrateUser{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{`CREATE TABLE IF NOT EXISTS "migrate_users" ("id" bigserial NOT NULL,` +
+		`"email" varchar(100) NOT NULL,"name" varchar(50),"age" smallint DEFAULT 0,"score" double precision,"bio" text,` +
+		`"avatar" bytea,"remark" varchar(20),"enabled" boolean,"created_at" timestamptz,"deleted_at" timestamptz,` +
+		`PRIMARY KEY ("id"))`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS "uidx_migrate_users_email" ON "migrate_users" ("email")`,
+		`CREATE INDEX IF NOT EXISTS "idx_name_age" ON "migrate_users" ("name","age")`}, stmts)
+
+	stmts, err = BuildCreateTableSQL(SQLite, migrateUser{})
+	assert.Nil(t, err)
+	assert.Contains(t, stmts[0], `("id" integer PRIMARY KEY AUTOINCREMENT,`)
+	assert.NotContains(t, stmts[0], `PRIMARY KEY ("id")`)
+}
+
+func TestBuildMigrateSQL(t *testing.T) {
+	zero := "0"
+	stmts, err := BuildMigrateSQL(MySQL, migrateUser{}, []ColumnInfo{
+		{Name: "id", Type: "bigint(20) unsigned"},
+		{Name: "email", Type: "varchar(100)"},
+		{Name: "name", Type: "varchar(20)", Nullable: true},
+		{Name: "age", Type: "tinyint(4)", Nullable: true, Default: &zero},
+		{Name: "score", Type: "double", Nullable: true},
+		{Name: "bio", Type: "longtext", Nullable: true},
+		{Name: "avatar", Type: "longblob", Nullable: true},
+		{Name: "remark", Type: "VARCHAR(20)", Nullable: true},
+		{Name: "enabled", Type: "tinyint(1)", Nullable: true},
+		{Name: "created_at", Type: "datetime(3)", Nullable: true},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE `migrate_users` MODIFY COLUMN `name` varchar(50)",
+		"ALTER TABLE `migrate_users` ADD COLUMN `deleted_at` datetime(3)",
+	}, stmts)
+
+	text, varchar := "'x'::text", "'0'::character varying"
+	stmts, err = BuildMigrateSQL(PostgreSQL, migrateUser{}, []ColumnInfo{
+		{Name: "id", Type: "bigint", Default: &text},
+		{Name: "email", Type: "character varying(100)", Nullable: true},
+		{Name: "name", Type: "character varying(50)", Nullable: true},
+		{Name: "age", Type: "integer", Nullable: true, Default: &varchar},
+		{Name: "score", Type: "double precision", Nullable: true},
+		{Name: "bio", Type: "text", Nullable: true, Default: &text},
+		{Name: "avatar", Type: "bytea", Nullable: true},
+		{Name: "remark", Type: "character varying(20)", Nullable: true},
+		{Name: "enabled", Type: "boolean", Nullable: true},
+		{Name: "created_at", Type: "timestamp with time zone"},
+		{Name: "deleted_at", Type: "timestamp with time zone", Nullable: true},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		`ALTER TABLE "migrate_users" ALTER COLUMN "email" SET NOT NULL`,
+		`ALTER TABLE "migrate_users" ALTER COLUMN "age" TYPE smallint`,
+		`ALTER TABLE "migrate_users" ALTER COLUMN "bio" DROP DEFAULT`,
+		`ALTER TABLE "migrate_users" ALTER COLUMN "created_at" DROP NOT NULL`,
+	}, stmts)
+
+	// 新增的列创建索引，not null 没有 default 时不加 NOT NULL
+	existing := []ColumnInfo{{Name: "id", Type: "bigint unsigned"}, {Name: "score", Type: "double", Nullable: true}}
+	stmts, err = BuildMigrateSQL(MySQL, migrateUser{}, existing)
+	assert.Nil(t, err)
+	assert.Contains(t, stmts, "ALTER TABLE `migrate_users` ADD COLUMN `email` varchar(100)")
+	assert.Contains(t, stmts, "ALTER TABLE `migrate_users` ADD COLUMN `age` tinyint DEFAULT 0")
+	assert.Equal(t, []string{
+		"ALTER TABLE `migrate_users` ADD UNIQUE INDEX `uidx_migrate_users_email` (`email`)",
+		"ALTER TABLE `migrate_users` ADD INDEX `idx_name_age` (`name`,`age`)",
+	}, stmts[len(stmts)-2:])
+
+	stmts, err = BuildMigrateSQL(SQLite, migrateUser{}, []ColumnInfo{{Name: "id", Type: "integer"}, {Name: "email", Type: "text"}})
+	assert.Nil(t, err)
+	assert.Equal(t, `CREATE INDEX IF NOT EXISTS "idx_name_age" ON "migrate_users" ("name","age")`, stmts[len(stmts)-1])
+	assert.NotContains(t, stmts, `CREATE UNIQUE INDEX IF NOT EXISTS "uidx_migrate_users_email" ON "migrate_users" ("email")`)
+}
+
+type indexedToken struct {
+	ID    int64
+	Token string `gorm:"size:64;index;uniqueIndex"`
+	Kind  string `gorm:"size:20;index:idx_kind;uniqueIndex:idx_kind"`
+}
+
+func TestSchemaIndexes(t *testing.T) {
+	schema, err := GetSchema(indexedToken{})
+	assert.Nil(t, err)
+	assert.Equal(t, []*Index{
+		{Name: "idx_indexed_tokens_token", Columns: []string{"token"}},
+		{Name: "uidx_indexed_tokens_token", Unique: true, Columns: []string{"token"}},
+		{Name: "idx_kind", Columns: []string{"kind"}},
+	}, schema.Indexes())
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go/ast"
 	"reflect"
+	"strings"
 
 	"github.com/joker-circus/gotools"
 	"github.com/joker-circus/gotools/inflection"
@@ -15,15 +16,19 @@ type Schema struct {
 	ModelType reflect.Type
 	Table     string
 
-	DBNames        []string
-	Fields         []*Field
-	FieldsByName   map[string]*Field
-	FieldsByDBName map[string]*Field
+	DBNames             []string
+	Fields              []*Field
+	FieldsByName        map[string]*Field
+	FieldsByDBName      map[string]*Field
+	PrimaryFields       []*Field
+	PrimaryFieldDBNames []string
 }
 
 type Field struct {
 	Name           string
 	DBName         string
+	FieldType      reflect.Type
+	PrimaryKey     bool
 	TagSettings    map[string]string
 	Schema         *Schema
 	EmbeddedSchema *Schema
@@ -84,6 +89,8 @@ func GetSchema(dest interface{}) (*Schema, error) {
 			field := &Field{
 				Name:        name,
 				DBName:      dbName,
+				FieldType:   fieldStruct.Type,
+				PrimaryKey:  isTrue(tagSetting["PRIMARYKEY"]) || isTrue(tagSetting["PRIMARY_KEY"]),
 				TagSettings: tagSetting,
 				Schema:      schema,
 			}
@@ -107,11 +114,98 @@ func GetSchema(dest interface{}) (*Schema, error) {
 		schema.FieldsByDBName[field.DBName] = field
 		schema.FieldsByName[field.Name] = field
 		schema.DBNames = append(schema.DBNames, field.DBName)
+		if field.PrimaryKey {
+			schema.PrimaryFields = append(schema.PrimaryFields, field)
+		}
+	}
+
+	// 与 gorm 一致，未声明主键时 ID 字段作为主键
+	if len(schema.PrimaryFields) == 0 {
+		if field, ok := schema.FieldsByName["ID"]; ok {
+			field.PrimaryKey = true
+			schema.PrimaryFields = append(schema.PrimaryFields, field)
+		}
+	}
+	for _, field := range schema.PrimaryFields {
+		schema.PrimaryFieldDBNames = append(schema.PrimaryFieldDBNames, field.DBName)
 	}
 
 	return schema, nil
 }
 
+// Index 为 index、uniqueIndex、unique 标签声明的索引
+type Index struct {
+	Name    string
+	Unique  bool
+	Columns []string
+}
+
+// Indexes 返回 index、uniqueIndex、unique 标签声明的索引，同名索引为联合索引，
+// 未指定名称时普通索引为 idx_表名_列名，唯一索引为 uidx_表名_列名，按字段顺序返回。
+func (schema *Schema) Indexes() []*Index {
+	var (
+		indexes []*Index
+		byName  = map[string]*Index{}
+	)
+	add := func(name string, unique bool, field *Field) {
+		name = strings.TrimSpace(strings.Split(name, ",")[0])
+		if name == "" || strings.EqualFold(name, "INDEX") || strings.EqualFold(name, "UNIQUEINDEX") {
+			prefix := "idx_"
+			if unique {
+				prefix = "uidx_"
+			}
+			name = prefix + schema.Table + "_" + field.DBName
+		}
+
+		index, ok := byName[name]
+		if !ok {
+			index = &Index{Name: name, Unique: unique}
+			byName[name] = index
+			indexes = append(indexes, index)
+		}
+		for _, column := range index.Columns {
+			if column == field.DBName {
+				return
+			}
+		}
+		index.Columns = append(index.Columns, field.DBName)
+	}
+
+	for _, field := range schema.Fields {
+		if v, ok := field.TagSettings["INDEX"]; ok {
+			add(v, false, field)
+		}
+		if v, ok := field.TagSettings["UNIQUEINDEX"]; ok {
+			add(v, true, field)
+		} else if isTrue(field.TagSettings["UNIQUE"]) {
+			add("", true, field)
+		}
+	}
+	return indexes
+}
+
+// UniqueIndexes 返回 uniqueIndex、unique 标签声明的唯一索引列，
+// 同名 uniqueIndex 为联合索引，按字段顺序返回。
+func (schema *Schema) UniqueIndexes() [][]string {
+	var result [][]string
+	for _, index := range schema.Indexes() {
+		if index.Unique {
+			result = append(result, index.Columns)
+		}
+	}
+	return result
+}
+
+// isTrue 判断标签值是否为真，如 primaryKey、primaryKey:true
+func isTrue(v string) bool {
+	switch strings.ToUpper(strings.TrimSpace(v)) {
+	case "", "FALSE":
+		return false
+	default:
+		return true
+	}
+}
+
 // 是否为内嵌结构体
 func (schema *Schema) embeddedSchema(fieldStruct reflect.StructField) (*Schema, error) {
 	fieldType := fieldStruct.Type
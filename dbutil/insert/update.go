@@ -0,0 +1,347 @@
+package insert
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	// ErrMissingPrimaryKey 结构体无主键或主键为零值
+	ErrMissingPrimaryKey = errors.New("primary key is required")
+	// ErrNoChanges 与原值相比没有变更的字段
+	ErrNoChanges = errors.New("no changed fields")
+)
+
+// 软删除字段的列名
+const deletedAtColumn = "deleted_at"
+
+// 生成按主键更新的预编译 SQL 语句及参数：
+//
+//	UPDATE `users` SET `name`=?,`updated_at`=? WHERE `id` = ?
+//
+// 更新除主键、created_at、deleted_at 外的所有列，updated_at 更新为当前时间；
+// 存在 deleted_at 列时只更新未删除的行。
+func BuildUpdateSQL(dialect Dialect, dest interface{}) (sql string, values []interface{}, err error) {
+	schema, rv, err := getStructValue(dest)
+	if err != nil {
+		return "", nil, err
+	}
+
+	row := getStructValueMap(rv, schema, nil)
+	return buildUpdateSQL(dialect, schema, row, updateColumns(schema))
+}
+
+// 生成只更新变更字段的预编译 SQL 语句及参数，变更字段通过与 original 逐列比较得出，
+// original 与 dest 须为同一类型的结构体。没有变更的字段时返回 ErrNoChanges。
+func BuildUpdateChangedSQL(dialect Dialect, original, dest interface{}) (sql string, values []interface{}, err error) {
+	schema, rv, err := getStructValue(dest)
+	if err != nil {
+		return "", nil, err
+	}
+
+	_, orv, err := getStructValue(original)
+	if err != nil {
+		return "", nil, err
+	}
+	if orv.Type() != rv.Type() {
+		return "", nil, fmt.Errorf("%w: original is %s, but dest is %s", ErrInvalidData, orv.Type(), rv.Type())
+	}
+
+	row := getStructValueMap(rv, schema, nil)
+	originalRow := getStructValueMap(orv, schema, nil)
+
+	var columns []string
+	for _, column := range updateColumns(schema) {
+		if column == "updated_at" {
+			continue
+		}
+		if !reflect.DeepEqual(row[column], originalRow[column]) {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		return "", nil, ErrNoChanges
+	}
+	if _, ok := schema.FieldsByDBName["updated_at"]; ok {
+		columns = append(columns, "updated_at")
+	}
+
+	return buildUpdateSQL(dialect, schema, row, columns)
+}
+
+// 生成批量更新的预编译 SQL 语句及参数，dest 为结构体切片，按主键更新 columns 列，
+// columns 为空时更新除主键、created_at、deleted_at 外的所有列：
+//
+//	UPDATE `users` SET `name`=CASE WHEN `id` = ? THEN ? WHEN `id` = ? THEN ? ELSE `name` END
+//	WHERE `id` IN (?,?)
+func BuildBulkUpdateSQL(dialect Dialect, dest interface{}, columns ...string) (sql string, values []interface{}, err error) {
+	schema, rvs, err := getStructs(dest)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(schema.PrimaryFieldDBNames) == 0 {
+		return "", nil, ErrMissingPrimaryKey
+	}
+	if len(columns) == 0 {
+		columns = updateColumns(schema)
+	}
+
+	now := time.Now()
+	rows := make([]map[string]interface{}, 0, len(rvs))
+	for _, rv := range rvs {
+		row := getStructValueMap(rv, schema, nil)
+		if _, ok := row["updated_at"]; ok {
+			row["updated_at"] = now
+		}
+		if !hasPrimaryKey(schema, row) {
+			return "", nil, ErrMissingPrimaryKey
+		}
+		rows = append(rows, row)
+	}
+
+	w := write{dialect: dialect}
+	w.WriteString("UPDATE ")
+	w.WriteColumn(schema.Table)
+	w.WriteString(" SET ")
+	for idx, column := range columns {
+		if _, ok := schema.FieldsByDBName[column]; !ok {
+			return "", nil, fmt.Errorf("%w: unknown column %s", ErrInvalidData, column)
+		}
+		if idx > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteColumn(column)
+		w.WriteString("=CASE")
+		for _, row := range rows {
+			w.WriteString(" WHEN ")
+			writePrimaryKeyCondition(&w, schema, row)
+			w.WriteString(" THEN ")
+			w.addVar(row[column])
+		}
+		w.WriteString(" ELSE ")
+		w.WriteColumn(column)
+		w.WriteString(" END")
+	}
+
+	w.WriteString(" WHERE ")
+	writePrimaryKeysIn(&w, schema, rows)
+	writeNotDeleted(&w, schema)
+	if w.err != nil {
+		return "", nil, w.err
+	}
+
+	return w.String(), w.vars, nil
+}
+
+// 生成按主键删除的预编译 SQL 语句及参数，dest 为结构体或结构体切片。
+// 存在 deleted_at 列时为软删除：
+//
+//	UPDATE `users` SET `deleted_at`=? WHERE `id` IN (?,?) AND `deleted_at` IS NULL
+//
+// 否则为：
+//
+//	DELETE FROM `users` WHERE `id` IN (?,?)
+func BuildDeleteSQL(dialect Dialect, dest interface{}) (sql string, values []interface{}, err error) {
+	return buildDeleteSQL(dialect, dest, false)
+}
+
+// 生成按主键删除的预编译 SQL 语句及参数，忽略 deleted_at 列，总是物理删除。
+func BuildUnscopedDeleteSQL(dialect Dialect, dest interface{}) (sql string, values []interface{}, err error) {
+	return buildDeleteSQL(dialect, dest, true)
+}
+
+func buildDeleteSQL(dialect Dialect, dest interface{}, unscoped bool) (string, []interface{}, error) {
+	schema, rvs, err := getStructs(dest)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(schema.PrimaryFieldDBNames) == 0 {
+		return "", nil, ErrMissingPrimaryKey
+	}
+
+	rows := make([]map[string]interface{}, 0, len(rvs))
+	for _, rv := range rvs {
+		row := getStructValueMap(rv, schema, nil)
+		if !hasPrimaryKey(schema, row) {
+			return "", nil, ErrMissingPrimaryKey
+		}
+		rows = append(rows, row)
+	}
+
+	w := write{dialect: dialect}
+	_, softDelete := schema.FieldsByDBName[deletedAtColumn]
+	if softDelete && !unscoped {
+		w.WriteString("UPDATE ")
+		w.WriteColumn(schema.Table)
+		w.WriteString(" SET ")
+		w.WriteColumn(deletedAtColumn)
+		w.WriteByte('=')
+		w.addVar(time.Now())
+		w.WriteString(" WHERE ")
+		writePrimaryKeysIn(&w, schema, rows)
+		writeNotDeleted(&w, schema)
+	} else {
+		w.WriteString("DELETE FROM ")
+		w.WriteColumn(schema.Table)
+		w.WriteString(" WHERE ")
+		writePrimaryKeysIn(&w, schema, rows)
+	}
+	if w.err != nil {
+		return "", nil, w.err
+	}
+
+	return w.String(), w.vars, nil
+}
+
+func buildUpdateSQL(dialect Dialect, schema *Schema, row map[string]interface{}, columns []string) (string, []interface{}, error) {
+	if len(schema.PrimaryFieldDBNames) == 0 || !hasPrimaryKey(schema, row) {
+		return "", nil, ErrMissingPrimaryKey
+	}
+
+	w := write{dialect: dialect}
+	w.WriteString("UPDATE ")
+	w.WriteColumn(schema.Table)
+	w.WriteString(" SET ")
+	for idx, column := range columns {
+		if idx > 0 {
+			w.WriteByte(',')
+		}
+		w.WriteColumn(column)
+		w.WriteByte('=')
+		if column == "updated_at" {
+			w.addVar(time.Now())
+		} else {
+			w.addVar(row[column])
+		}
+	}
+
+	w.WriteString(" WHERE ")
+	writePrimaryKeyCondition(&w, schema, row)
+	writeNotDeleted(&w, schema)
+	if w.err != nil {
+		return "", nil, w.err
+	}
+
+	return w.String(), w.vars, nil
+}
+
+// 可更新的列：除主键、created_at、deleted_at 外的所有列
+func updateColumns(schema *Schema) []string {
+	excluded := map[string]bool{"created_at": true, deletedAtColumn: true}
+	for _, column := range schema.PrimaryFieldDBNames {
+		excluded[column] = true
+	}
+
+	var columns []string
+	for _, column := range schema.DBNames {
+		if !excluded[column] {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+func hasPrimaryKey(schema *Schema, row map[string]interface{}) bool {
+	for _, column := range schema.PrimaryFieldDBNames {
+		v := row[column]
+		if v == nil || reflect.ValueOf(v).IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+// `id` = ? AND `tenant_id` = ?
+func writePrimaryKeyCondition(w *write, schema *Schema, row map[string]interface{}) {
+	for idx, column := range schema.PrimaryFieldDBNames {
+		if idx > 0 {
+			w.WriteString(" AND ")
+		}
+		w.WriteColumn(column)
+		w.WriteString(" = ")
+		w.addVar(row[column])
+	}
+}
+
+// `id` IN (?,?)，联合主键时为 (`a` = ? AND `b` = ?) OR (...)
+func writePrimaryKeysIn(w *write, schema *Schema, rows []map[string]interface{}) {
+	if len(schema.PrimaryFieldDBNames) == 1 {
+		column := schema.PrimaryFieldDBNames[0]
+		w.WriteColumn(column)
+		w.WriteString(" IN (")
+		for idx, row := range rows {
+			if idx > 0 {
+				w.WriteByte(',')
+			}
+			w.addVar(row[column])
+		}
+		w.WriteByte(')')
+		return
+	}
+
+	w.WriteByte('(')
+	for idx, row := range rows {
+		if idx > 0 {
+			w.WriteString(" OR ")
+		}
+		w.WriteByte('(')
+		writePrimaryKeyCondition(w, schema, row)
+		w.WriteByte(')')
+	}
+	w.WriteByte(')')
+}
+
+// 存在 deleted_at 列时只处理未删除的行
+func writeNotDeleted(w *write, schema *Schema) {
+	if _, ok := schema.FieldsByDBName[deletedAtColumn]; ok {
+		w.WriteString(" AND ")
+		w.WriteColumn(deletedAtColumn)
+		w.WriteString(" IS NULL")
+	}
+}
+
+// 获取结构体的 Schema 及值
+func getStructValue(dest interface{}) (*Schema, reflect.Value, error) {
+	schema, err := GetSchema(dest)
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(dest))
+	if rv.Kind() != reflect.Struct {
+		return nil, reflect.Value{}, ErrInvalidValue
+	}
+	return schema, rv, nil
+}
+
+// 获取结构体或结构体切片的 Schema 及各结构体的值
+func getStructs(dest interface{}) (*Schema, []reflect.Value, error) {
+	schema, err := GetSchema(dest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(dest))
+	switch rv.Kind() {
+	case reflect.Struct:
+		return schema, []reflect.Value{rv}, nil
+	case reflect.Slice, reflect.Array:
+		if rv.Len() == 0 {
+			return nil, nil, ErrEmptySlice
+		}
+
+		rvs := make([]reflect.Value, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			elem := reflect.Indirect(rv.Index(i))
+			if !elem.IsValid() {
+				return nil, nil, fmt.Errorf("slice data #%v is invalid: %w", i, ErrInvalidData)
+			}
+			rvs = append(rvs, elem)
+		}
+		return schema, rvs, nil
+	default:
+		return nil, nil, ErrInvalidValue
+	}
+}
@@ -0,0 +1,80 @@
+package insert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type updateUser struct {
+	ID        int64
+	Name      string
+	Age       int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+}
+
+type updateMember struct {
+	GroupID int64 `gorm:"primaryKey"`
+	UserID  int64 `gorm:"primaryKey"`
+	Role    string
+}
+
+func TestBuildUpdateSQL(t *testing.T) {
+	u := updateUser{ID: 1, Name: "a", Age: 18}
+	sql, values, err := BuildUpdateSQL(MySQL, &u)
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE `update_users` SET `name`=?,`age`=?,`updated_at`=? WHERE `id` = ? AND `deleted_at` IS NULL", sql)
+	assert.Equal(t, "a", values[0])
+	assert.Equal(t, int64(1), values[3])
+
+	_, _, err = BuildUpdateSQL(MySQL, updateUser{Name: "a"})
+	assert.ErrorIs(t, err, ErrMissingPrimaryKey)
+
+	changed := u
+	changed.Age = 20
+	sql, values, err = BuildUpdateChangedSQL(PostgreSQL, u, changed)
+	assert.Nil(t, err)
+	assert.Equal(t, `UPDATE "update_users" SET "age"=$1,"updated_at"=$2 WHERE "id" = $3 AND "deleted_at" IS NULL`, sql)
+	assert.Equal(t, 20, values[0])
+
+	_, _, err = BuildUpdateChangedSQL(PostgreSQL, u, u)
+	assert.ErrorIs(t, err, ErrNoChanges)
+}
+
+func TestBuildBulkUpdateSQL(t *testing.T) {
+	members := []updateMember{{GroupID: 1, UserID: 2, Role: "admin"}, {GroupID: 1, UserID: 3, Role: "guest"}}
+	sql, values, err := BuildBulkUpdateSQL(MySQL, members)
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE `update_members` SET `role`=CASE"+
+		" WHEN `group_id` = ? AND `user_id` = ? THEN ? WHEN `group_id` = ? AND `user_id` = ? THEN ? ELSE `role` END"+
+		" WHERE ((`group_id` = ? AND `user_id` = ?) OR (`group_id` = ? AND `user_id` = ?))", sql)
+	assert.Equal(t, []interface{}{int64(1), int64(2), "admin", int64(1), int64(3), "guest",
+		int64(1), int64(2), int64(1), int64(3)}, values)
+
+	users := []updateUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	sql, values, err = BuildBulkUpdateSQL(MySQL, users, "name")
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE `update_users` SET `name`=CASE WHEN `id` = ? THEN ? WHEN `id` = ? THEN ? ELSE `name` END"+
+		" WHERE `id` IN (?,?) AND `deleted_at` IS NULL", sql)
+	assert.Len(t, values, 6)
+}
+
+func TestBuildDeleteSQL(t *testing.T) {
+	users := []updateUser{{ID: 1}, {ID: 2}}
+	sql, values, err := BuildDeleteSQL(MySQL, users)
+	assert.Nil(t, err)
+	assert.Equal(t, "UPDATE `update_users` SET `deleted_at`=? WHERE `id` IN (?,?) AND `deleted_at` IS NULL", sql)
+	assert.Len(t, values, 3)
+
+	sql, values, err = BuildUnscopedDeleteSQL(MySQL, users)
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETE FROM `update_users` WHERE `id` IN (?,?)", sql)
+	assert.Equal(t, []interface{}{int64(1), int64(2)}, values)
+
+	sql, _, err = BuildDeleteSQL(SQLite, updateMember{GroupID: 1, UserID: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, `DELETE FROM "update_members" WHERE (("group_id" = ? AND "user_id" = ?))`, sql)
+}
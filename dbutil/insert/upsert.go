@@ -0,0 +1,164 @@
+package insert
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoConflictColumns 冲突列为空，PostgreSQL、SQLite 的 DO UPDATE 必须指定冲突列
+var ErrNoConflictColumns = errors.New("conflict columns are required")
+
+// OnConflict 定义唯一键冲突时的行为。
+//
+//	MySQL:              ON DUPLICATE KEY UPDATE `a`=VALUES(`a`)，DoNothing 时为 INSERT IGNORE
+//	PostgreSQL、SQLite: ON CONFLICT ("id") DO UPDATE SET "a"=excluded."a"，或 DO NOTHING
+type OnConflict struct {
+	// Columns 冲突列，为空时使用主键，无主键时使用第一个唯一索引
+	Columns []string
+	// DoNothing 冲突时忽略该行
+	DoNothing bool
+	// DoUpdates 冲突时更新的列，为空时更新除冲突列、主键及 created_at 外的所有列，
+	// 无可更新的列时忽略该行
+	DoUpdates []string
+}
+
+// 生成 upsert 预编译 SQL 语句及参数。
+func BuildUpsertSQL(dialect Dialect, dest interface{}, conflict OnConflict) (sql string, values []interface{}, err error) {
+	schema, sqlBuild, err := buildSchemaSQL(dest)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sqlBuild.Dialect = dialect
+	sqlBuild.OnConflict = conflict.resolve(schema)
+	return sqlBuild.preSQLWithValues()
+}
+
+// 生成指定方言的批量创建预编译 SQL 语句及参数。
+func BuildDialectPreSQL(dialect Dialect, dest interface{}) (sql string, values []interface{}, err error) {
+	sqlBuild, err := buildSQL(dest)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sqlBuild.Dialect = dialect
+	return sqlBuild.preSQLWithValues()
+}
+
+func (s SQL) preSQLWithValues() (string, []interface{}, error) {
+	preSQL, err := s.PreSQL()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var values []interface{}
+	for _, v := range s.Values {
+		values = append(values, v...)
+	}
+
+	return preSQL, values, nil
+}
+
+// resolve 根据 schema 补全默认的冲突列及更新列
+func (c OnConflict) resolve(schema *Schema) *OnConflict {
+	resolved := OnConflict{
+		Columns:   c.Columns,
+		DoNothing: c.DoNothing,
+		DoUpdates: c.DoUpdates,
+	}
+
+	if len(resolved.Columns) == 0 {
+		resolved.Columns = schema.PrimaryFieldDBNames
+		if indexes := schema.UniqueIndexes(); len(resolved.Columns) == 0 && len(indexes) > 0 {
+			resolved.Columns = indexes[0]
+		}
+	}
+
+	if !resolved.DoNothing && len(resolved.DoUpdates) == 0 {
+		excluded := map[string]bool{"created_at": true}
+		for _, column := range resolved.Columns {
+			excluded[column] = true
+		}
+		for _, column := range schema.PrimaryFieldDBNames {
+			excluded[column] = true
+		}
+
+		for _, column := range schema.DBNames {
+			if !excluded[column] {
+				resolved.DoUpdates = append(resolved.DoUpdates, column)
+			}
+		}
+		resolved.DoNothing = len(resolved.DoUpdates) == 0
+	}
+
+	return &resolved
+}
+
+func (s SQL) writeInsertInto(w *write) {
+	if s.OnConflict != nil && s.OnConflict.DoNothing && s.Dialect == MySQL {
+		w.WriteString("INSERT IGNORE INTO ")
+	} else {
+		w.WriteString("INSERT INTO ")
+	}
+	w.WriteColumn(s.Table)
+	w.WriteByte(' ')
+}
+
+func (s SQL) buildOnConflict(w *write) {
+	c := s.OnConflict
+	if c == nil {
+		return
+	}
+
+	switch s.Dialect {
+	case MySQL:
+		if c.DoNothing {
+			return
+		}
+
+		w.WriteString(" ON DUPLICATE KEY UPDATE ")
+		for idx, column := range c.DoUpdates {
+			if idx > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteColumn(column)
+			w.WriteString("=VALUES(")
+			w.WriteColumn(column)
+			w.WriteByte(')')
+		}
+
+	case PostgreSQL, SQLite:
+		w.WriteString(" ON CONFLICT ")
+		if len(c.Columns) > 0 {
+			w.WriteByte('(')
+			for idx, column := range c.Columns {
+				if idx > 0 {
+					w.WriteByte(',')
+				}
+				w.WriteColumn(column)
+			}
+			w.WriteString(") ")
+		} else if !c.DoNothing {
+			w.Error(ErrNoConflictColumns)
+			return
+		}
+
+		if c.DoNothing {
+			w.WriteString("DO NOTHING")
+			return
+		}
+
+		w.WriteString("DO UPDATE SET ")
+		for idx, column := range c.DoUpdates {
+			if idx > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteColumn(column)
+			w.WriteString("=excluded.")
+			w.WriteColumn(column)
+		}
+
+	default:
+		w.Error(fmt.Errorf("%w: upsert is not supported by %s", ErrUnsupportedDriver, s.Dialect))
+	}
+}
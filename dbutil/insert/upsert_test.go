@@ -0,0 +1,59 @@
+package insert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type upsertUser struct {
+	ID    int64
+	Email string `gorm:"uniqueIndex"`
+	Name  string
+	Age   int
+}
+
+type upsertTag struct {
+	Name  string `gorm:"uniqueIndex:idx_name_kind"`
+	Kind  string `gorm:"uniqueIndex:idx_name_kind"`
+	Count int
+}
+
+func TestBuildUpsertSQL(t *testing.T) {
+	users := []upsertUser{{ID: 1, Email: "a@x.com", Name: "a", Age: 18}, {ID: 2, Email: "b@x.com", Name: "b"}}
+
+	sql, values, err := BuildUpsertSQL(MySQL, users, OnConflict{})
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO `upsert_users` (`id`,`email`,`name`,`age`) VALUES (?,?,?,?),(?,?,?,?)"+
+		" ON DUPLICATE KEY UPDATE `email`=VALUES(`email`),`name`=VALUES(`name`),`age`=VALUES(`age`)", sql)
+	assert.Len(t, values, 8)
+
+	sql, _, err = BuildUpsertSQL(MySQL, users, OnConflict{DoNothing: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT IGNORE INTO `upsert_users` (`id`,`email`,`name`,`age`) VALUES (?,?,?,?),(?,?,?,?)", sql)
+
+	sql, _, err = BuildUpsertSQL(PostgreSQL, users, OnConflict{Columns: []string{"email"}, DoUpdates: []string{"name"}})
+	assert.Nil(t, err)
+	assert.Equal(t, `INSERT INTO "upsert_users" ("id","email","name","age") VALUES ($1,$2,$3,$4),($5,$6,$7,$8)`+
+		` ON CONFLICT ("email") DO UPDATE SET "name"=excluded."name"`, sql)
+
+	sql, _, err = BuildUpsertSQL(SQLite, []upsertTag{{Name: "go", Kind: "lang", Count: 1}}, OnConflict{})
+	assert.Nil(t, err)
+	assert.Equal(t, `INSERT INTO "upsert_tags" ("name","kind","count") VALUES (?,?,?)`+
+		` ON CONFLICT ("name","kind") DO UPDATE SET "count"=excluded."count"`, sql)
+
+	_, _, err = BuildUpsertSQL(Oracle, users, OnConflict{})
+	assert.ErrorIs(t, err, ErrUnsupportedDriver)
+}
+
+func TestSQLExplainDialect(t *testing.T) {
+	s := SQL{Table: "users", Columns: []string{"id", "name"}, Values: [][]interface{}{{1, "a"}, {2, "b"}}, Dialect: PostgreSQL}
+	sql, err := s.ExplainSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, `INSERT INTO "users" ("id","name") VALUES (1,'a'),(2,'b')`, sql)
+
+	s.Dialect = MySQL
+	sql, err = s.BuildInsertSQL()
+	assert.Nil(t, err)
+	assert.Equal(t, "INSERT INTO `users` (`id`,`name`) VALUES (1,'a'),(2,'b')", sql)
+}
@@ -50,11 +50,19 @@ type Builder interface {
 
 type write struct {
 	strings.Builder
-	err error
+	dialect Dialect
+	vars    []interface{}
+	err     error
+}
+
+// addVar 写入占位符并记录参数
+func (w *write) addVar(v interface{}) {
+	w.vars = append(w.vars, v)
+	w.WriteString(w.dialect.Placeholder(len(w.vars)))
 }
 
 func (w *write) WriteColumn(field interface{}) {
-	w.WriteString(fmt.Sprintf("`%v`", field))
+	w.WriteString(w.dialect.Quote(fmt.Sprint(field)))
 }
 
 func (w *write) WriteValues(writer Writer, vars ...interface{}) {
@@ -144,12 +152,18 @@ type SQL struct {
 	Table   string
 	Columns []string
 	Values  [][]interface{}
+
+	// Dialect 决定标识符引号及占位符，默认 MySQL
+	Dialect Dialect
+	// OnConflict 不为空时生成 upsert 语句
+	OnConflict *OnConflict
 }
 
 func (s SQL) BuildInsertSQL() (string, error) {
-	var w write
-	w.WriteString(fmt.Sprintf("INSERT INTO `%s` ", s.Table))
+	w := write{dialect: s.Dialect}
+	s.writeInsertInto(&w)
 	s.buildInsertSQL(&w)
+	s.buildOnConflict(&w)
 	if w.err != nil {
 		return "", w.err
 	}
@@ -196,12 +210,14 @@ func (s SQL) ExplainSQL() (string, error) {
 		args = append(args, v...)
 	}
 
-	return ExplainSQL(preSQL, nil, `'`, args...), nil
+	return ExplainSQL(preSQL, s.Dialect.numericPlaceholder(), `'`, args...), nil
 }
+
 func (s SQL) PreSQL() (string, error) {
-	var w write
-	w.WriteString(fmt.Sprintf("INSERT INTO `%s` ", s.Table))
+	w := write{dialect: s.Dialect}
+	s.writeInsertInto(&w)
 	s.buildPreSQL(&w)
+	s.buildOnConflict(&w)
 	if w.err != nil {
 		return "", w.err
 	}
@@ -232,7 +248,7 @@ func (s SQL) buildPreSQL(builder Builder) {
 				if j > 0 {
 					builder.WriteByte(',')
 				}
-				builder.WriteByte('?')
+				builder.WriteString(s.Dialect.Placeholder(idx*len(s.Columns) + j + 1))
 			}
 			builder.WriteByte(')')
 		}
@@ -3,84 +3,162 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 )
 
 // chain call method to create SQL
+// The select and group by expressions are built in the order they are added.
 type Query struct {
 	TableName   string
-	selectExpr  map[string]string
-	filterExpr  []string
-	groupByExpr map[string]string
+	dialect     Dialect
+	distinct    bool
+	fromQuery   *Query
+	fromAlias   string
+	withExpr    []namedQuery
+	selectExpr  []selectItem
+	joinExpr    []join
+	filterExpr  []condition
+	groupByExpr []selectItem
 	aggExpr     []string
-	havingExpr  []string
+	havingExpr  []condition
+	unionExpr   []union
 	orderByExpr []string
 	limit       int
 	offset      int
 }
 
+type selectItem struct {
+	field string
+	alias string
+}
+
+type namedQuery struct {
+	name  string
+	query *Query
+}
+
+type join struct {
+	kind  string
+	table string
+	query *Query
+	on    condition
+}
+
+type union struct {
+	all   bool
+	query *Query
+}
+
+// UseDialect sets the dialect used by Build, MySQL by default.
+// The dialects of the subqueries are ignored.
+func (q *Query) UseDialect(d Dialect) *Query {
+	q.dialect = d
+	return q
+}
+
 func (q *Query) From(name string) *Query {
-	if q.TableName == "" {
+	if q.TableName == "" && q.fromQuery == nil {
 		q.TableName = name
 	}
 	return q
 }
 
-func (q *Query) SelectAs(exprs map[string]string) *Query {
-	if q.selectExpr == nil {
-		q.selectExpr = make(map[string]string)
+// FromQuery selects from the subquery, e.g. "from (select ...) as alias".
+func (q *Query) FromQuery(sub *Query, alias string) *Query {
+	if q.TableName == "" && q.fromQuery == nil && sub != nil {
+		q.fromQuery = sub
+		q.fromAlias = strings.TrimSpace(alias)
 	}
+	return q
+}
 
-	for field, alias := range exprs {
-		if strings.TrimSpace(field) == "" {
-			continue
-		}
+// With adds a common table expression, e.g. "with name as (select ...)".
+func (q *Query) With(name string, sub *Query) *Query {
+	name = strings.TrimSpace(name)
+	if name == "" || sub == nil {
+		return q
+	}
 
-		q.selectExpr[strings.TrimSpace(field)] = strings.TrimSpace(alias)
+	for i, w := range q.withExpr {
+		if w.name == name {
+			q.withExpr[i].query = sub
+			return q
+		}
 	}
+	q.withExpr = append(q.withExpr, namedQuery{name: name, query: sub})
+	return q
+}
 
+// Distinct selects distinct rows.
+func (q *Query) Distinct() *Query {
+	q.distinct = true
 	return q
 }
 
-func (q *Query) Select(exprs ...string) *Query {
-	if q.selectExpr == nil {
-		q.selectExpr = make(map[string]string)
+// SelectAs adds the field-alias pairs ordered by the fields,
+// since the order of a map is random.
+func (q *Query) SelectAs(exprs map[string]string) *Query {
+	fields := make([]string, 0, len(exprs))
+	for field := range exprs {
+		fields = append(fields, field)
 	}
+	sort.Strings(fields)
 
-	for _, expr := range exprs {
-		if strings.TrimSpace(expr) == "" {
-			continue
-		}
+	for _, field := range fields {
+		q.selectExpr = appendItem(q.selectExpr, field, exprs[field])
+	}
 
-		var field, alias string
-		arr := strings.Split(expr, " as ")
-		if len(arr) == 2 {
-			field = strings.TrimSpace(arr[0])
-			alias = strings.TrimSpace(arr[1])
-		} else if len(arr) == 1 {
-			field = strings.TrimSpace(arr[0])
-			alias = ""
-		}
+	return q
+}
 
-		q.selectExpr[field] = alias
+func (q *Query) Select(exprs ...string) *Query {
+	for _, expr := range exprs {
+		field, alias := splitAlias(expr)
+		q.selectExpr = appendItem(q.selectExpr, field, alias)
 	}
 
 	return q
 }
 
 func (q *Query) Selected() string {
-	var selected []string
-	for field, alias := range q.selectExpr {
-		if alias == "" {
-			selected = append(selected, field)
-		} else {
-			selected = append(selected, field+" as "+alias)
-		}
+	return joinItems(q.selectExpr, true, func(s string) string { return s })
+}
+
+// Join adds an inner join, e.g. Join("orders as o", "o.user_id = users.id").
+// The on expression can have "?" placeholders the same as WhereArgs.
+func (q *Query) Join(table, on string, args ...interface{}) *Query {
+	return q.join("join", table, nil, on, args)
+}
+
+// LeftJoin adds a left join, the same as Join.
+func (q *Query) LeftJoin(table, on string, args ...interface{}) *Query {
+	return q.join("left join", table, nil, on, args)
+}
+
+// JoinQuery adds an inner join of the subquery, e.g. "join (select ...) as alias".
+func (q *Query) JoinQuery(sub *Query, alias, on string, args ...interface{}) *Query {
+	return q.join("join", alias, sub, on, args)
+}
+
+// LeftJoinQuery adds a left join of the subquery, the same as JoinQuery.
+func (q *Query) LeftJoinQuery(sub *Query, alias, on string, args ...interface{}) *Query {
+	return q.join("left join", alias, sub, on, args)
+}
+
+func (q *Query) join(kind, table string, sub *Query, on string, args []interface{}) *Query {
+	table = strings.TrimSpace(table)
+	if table == "" && sub == nil {
+		return q
 	}
 
-	return strings.Join(selected, ", ")
+	cond, _ := newCondition("", on, args)
+	q.joinExpr = append(q.joinExpr, join{kind: kind, table: table, query: sub, on: cond})
+	return q
 }
 
+// Where adds raw filter expressions, which must not contain user input.
+// Use WhereArgs to bind arguments.
 func (q *Query) Where(exprs ...string) *Query {
 	return q.Filter(exprs...)
 }
@@ -89,53 +167,87 @@ func (q *Query) WhereIn(exprs map[string][]string) *Query {
 	return q.FilterIn(exprs)
 }
 
+// WhereArgs adds a filter expression with "?" placeholders bound to args,
+// e.g. WhereArgs("age > ? and name = ?", 18, name).
+// A slice argument is expanded to a placeholder list, e.g.
+// WhereArgs("id in (?)", []int{1, 2}) is "id in (?,?)",
+// and a *Query argument is a subquery, e.g.
+// WhereArgs("id in ?", sub) is "id in (select ...)".
+func (q *Query) WhereArgs(expr string, args ...interface{}) *Query {
+	if cond, ok := newCondition("", expr, args); ok {
+		q.filterExpr = append(q.filterExpr, cond)
+	}
+	return q
+}
+
+// WhereInArgs adds a filter "field in (?,?,...)" bound to values,
+// or "field in (select ...)" if the only value is a *Query.
+// The filter is always false if values is empty.
+func (q *Query) WhereInArgs(field string, values ...interface{}) *Query {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return q
+	}
+
+	if len(values) == 0 {
+		q.filterExpr = append(q.filterExpr, condition{expr: "1 = 0"})
+		return q
+	}
+	if _, ok := values[0].(*Query); ok && len(values) == 1 {
+		q.filterExpr = append(q.filterExpr, condition{column: field, expr: " in ?", args: values})
+		return q
+	}
+
+	q.filterExpr = append(q.filterExpr, condition{
+		column: field,
+		expr:   " in (" + placeholders(len(values)) + ")",
+		args:   values,
+	})
+	return q
+}
+
 func (q *Query) Filter(exprs ...string) *Query {
-	q.filterExpr = q.append(q.filterExpr, exprs...)
+	q.filterExpr = q.appendCondition(q.filterExpr, exprs...)
 	return q
 }
 
 // exprs key-value: field-inValues
 // each key-value means an independent filter
+// The values are bound as arguments by Build, the filters are ordered
+// by the fields.
 func (q *Query) FilterIn(exprs map[string][]string) *Query {
-	filters := make([]string, 0)
+	fields := make([]string, 0, len(exprs))
+	for field := range exprs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
 
-	for field, values := range exprs {
-		filters = append(filters, fmt.Sprintf("%s in ('%s')", field, strings.Join(values, "','")))
+	for _, field := range fields {
+		values := exprs[field]
+		if len(values) == 0 {
+			values = []string{""}
+		}
+
+		args := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			args = append(args, v)
+		}
+		q.WhereInArgs(field, args...)
 	}
 
-	return q.Filter(filters...)
+	return q
 }
 
 func (q *Query) GroupBy(exprs ...string) *Query {
-	if q.groupByExpr == nil {
-		q.groupByExpr = make(map[string]string)
-	}
-
 	for _, expr := range exprs {
-		if strings.TrimSpace(expr) == "" {
-			continue
-		}
-
-		var field, alias string
-		arr := strings.Split(expr, " as ")
-		if len(arr) == 2 {
-			field = strings.TrimSpace(arr[0])
-			alias = strings.TrimSpace(arr[1])
-		} else if len(arr) == 1 {
-			field = strings.TrimSpace(arr[0])
-			alias = ""
-		}
-		q.groupByExpr[field] = alias
+		field, alias := splitAlias(expr)
+		q.groupByExpr = appendItem(q.groupByExpr, field, alias)
 	}
 	return q
 }
 
 func (q *Query) GroupByKeys() string {
-	var keys []string
-	for field := range q.groupByExpr {
-		keys = append(keys, field)
-	}
-	return strings.Join(keys, ", ")
+	return joinItems(q.groupByExpr, false, func(s string) string { return s })
 }
 
 func (q *Query) Agg(exprs ...string) *Query {
@@ -144,7 +256,33 @@ func (q *Query) Agg(exprs ...string) *Query {
 }
 
 func (q *Query) Having(exprs ...string) *Query {
-	q.havingExpr = q.append(q.havingExpr, exprs...)
+	q.havingExpr = q.appendCondition(q.havingExpr, exprs...)
+	return q
+}
+
+// HavingArgs adds a having expression with "?" placeholders bound to args,
+// the same as WhereArgs.
+func (q *Query) HavingArgs(expr string, args ...interface{}) *Query {
+	if cond, ok := newCondition("", expr, args); ok {
+		q.havingExpr = append(q.havingExpr, cond)
+	}
+	return q
+}
+
+// Union appends the rows of the query, duplicates are removed.
+// OrderBy, Limit and Offset of q apply to the result of the union.
+func (q *Query) Union(sub *Query) *Query {
+	if sub != nil {
+		q.unionExpr = append(q.unionExpr, union{query: sub})
+	}
+	return q
+}
+
+// UnionAll appends all the rows of the query, the same as Union.
+func (q *Query) UnionAll(sub *Query) *Query {
+	if sub != nil {
+		q.unionExpr = append(q.unionExpr, union{all: true, query: sub})
+	}
 	return q
 }
 
@@ -163,13 +301,39 @@ func (q *Query) Offset(offset int) *Query {
 	return q
 }
 
+// String returns the SQL with the arguments inlined, which is expected to
+// be used in logs. Use Build to execute the query.
 func (q *Query) String() (string, error) {
+	sql, _, err := q.build(true)
+	return sql, err
+}
+
+// Build returns the SQL with the placeholders of the dialect and the bound
+// arguments, plain identifiers like table and column names are quoted.
+func (q *Query) Build() (string, []interface{}, error) {
+	return q.build(false)
+}
+
+func (q *Query) build(explain bool) (string, []interface{}, error) {
+	b := &sqlBuilder{dialect: q.dialect, explain: explain}
+	sql, err := q.buildWith(b)
+	if err != nil {
+		return "", nil, err
+	}
+	return sql, b.args, nil
+}
+
+// buildWith builds the query with b, which is shared by the subqueries
+// so that the placeholders are numbered in order.
+func (q *Query) buildWith(b *sqlBuilder) (string, error) {
 	// GroupBy() must be called explicitly while calling Agg()
 	if len(q.aggExpr) > 0 && len(q.groupByExpr) == 0 {
 		return "", errors.New("group by keys are expected when accept agg expr")
 	}
 
-	q.SelectAs(q.groupByExpr)
+	for _, item := range q.groupByExpr {
+		q.selectExpr = appendItem(q.selectExpr, item.field, item.alias)
+	}
 	q.Select(q.aggExpr...)
 
 	// select expression is expected
@@ -177,15 +341,80 @@ func (q *Query) String() (string, error) {
 		return "", errors.New("nothing selected")
 	}
 
-	template := fmt.Sprintf("\nselect %s \nfrom %s\n", q.Selected(), q.TableName)
+	template := "\n"
+	if len(q.withExpr) > 0 {
+		ctes := make([]string, 0, len(q.withExpr))
+		for _, w := range q.withExpr {
+			sub, err := b.subquery(w.query)
+			if err != nil {
+				return "", err
+			}
+			ctes = append(ctes, b.ident(w.name)+" as "+sub)
+		}
+		template += fmt.Sprintf("with %s\n", strings.Join(ctes, ", "))
+	}
+
+	selectKeyword := "select"
+	if q.distinct {
+		selectKeyword = "select distinct"
+	}
+	from := b.table(q.TableName)
+	if q.fromQuery != nil {
+		sub, err := b.subquery(q.fromQuery)
+		if err != nil {
+			return "", err
+		}
+		from = b.alias(sub, q.fromAlias)
+	}
+	template += fmt.Sprintf("%s %s \nfrom %s\n", selectKeyword, joinItems(q.selectExpr, true, b.ident), from)
+
+	for _, j := range q.joinExpr {
+		table := b.table(j.table)
+		if j.query != nil {
+			sub, err := b.subquery(j.query)
+			if err != nil {
+				return "", err
+			}
+			table = b.alias(sub, j.table)
+		}
+		on, err := b.condition(j.on)
+		if err != nil {
+			return "", err
+		}
+		if on == "" {
+			template += fmt.Sprintf("%s %s\n", j.kind, table)
+		} else {
+			template += fmt.Sprintf("%s %s on %s\n", j.kind, table, on)
+		}
+	}
+
 	if len(q.filterExpr) > 0 {
-		template += fmt.Sprintf("where (%s)\n", strings.Join(q.filterExpr, ")\n  and ("))
+		where, err := b.conditions(q.filterExpr, ")\n  and (")
+		if err != nil {
+			return "", err
+		}
+		template += fmt.Sprintf("where (%s)\n", where)
 	}
 	if len(q.groupByExpr) > 0 {
-		template += fmt.Sprintf("group by %s\n", q.GroupByKeys())
+		template += fmt.Sprintf("group by %s\n", joinItems(q.groupByExpr, false, b.ident))
 	}
 	if len(q.havingExpr) > 0 {
-		template += fmt.Sprintf("having (%s)\n", strings.Join(q.havingExpr, ")\n   and ("))
+		having, err := b.conditions(q.havingExpr, ")\n   and (")
+		if err != nil {
+			return "", err
+		}
+		template += fmt.Sprintf("having (%s)\n", having)
+	}
+	for _, u := range q.unionExpr {
+		sub, err := u.query.buildWith(b)
+		if err != nil {
+			return "", err
+		}
+		if u.all {
+			template += "union all" + sub
+		} else {
+			template += "union" + sub
+		}
 	}
 	if len(q.orderByExpr) > 0 {
 		template += fmt.Sprintf("order by %s\n", strings.Join(q.orderByExpr, ", "))
@@ -200,6 +429,29 @@ func (q *Query) String() (string, error) {
 	return template, nil
 }
 
+func (q *Query) appendCondition(conds []condition, exprs ...string) []condition {
+	for _, expr := range exprs {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+
+		cond := condition{expr: expr}
+		exist := false
+		for _, c := range conds {
+			if c.raw() && c.expr == expr {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			conds = append(conds, cond)
+		}
+	}
+
+	return conds
+}
+
 func (q *Query) append(arr []string, elems ...string) []string {
 	for _, elem := range elems {
 		elem = strings.TrimSpace(elem)
@@ -212,6 +464,44 @@ func (q *Query) append(arr []string, elems ...string) []string {
 	return arr
 }
 
+// splitAlias splits "field as alias" into the field and the alias.
+func splitAlias(expr string) (field, alias string) {
+	arr := strings.Split(expr, " as ")
+	if len(arr) == 2 {
+		return strings.TrimSpace(arr[0]), strings.TrimSpace(arr[1])
+	}
+	return strings.TrimSpace(expr), ""
+}
+
+// appendItem appends the field, or updates the alias if it exists.
+func appendItem(items []selectItem, field, alias string) []selectItem {
+	field = strings.TrimSpace(field)
+	if field == "" {
+		return items
+	}
+
+	alias = strings.TrimSpace(alias)
+	for i, item := range items {
+		if item.field == field {
+			items[i].alias = alias
+			return items
+		}
+	}
+	return append(items, selectItem{field: field, alias: alias})
+}
+
+func joinItems(items []selectItem, withAlias bool, ident func(string) string) string {
+	exprs := make([]string, 0, len(items))
+	for _, item := range items {
+		if !withAlias || item.alias == "" {
+			exprs = append(exprs, ident(item.field))
+		} else {
+			exprs = append(exprs, ident(item.field)+" as "+ident(item.alias))
+		}
+	}
+	return strings.Join(exprs, ", ")
+}
+
 func (q *Query) contains(arr []string, elem string) bool {
 	for _, v := range arr {
 		if v == elem {
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuild(t *testing.T) {
+	q := (&Query{}).From("users").
+		Select("name as n").
+		WhereArgs("age > ? and note <> '?'", 18).
+		WhereInArgs("id", 1, 2).
+		WhereArgs("type in (?)", []string{"a", "b"}).
+		HavingArgs("count(*) > ?", 3).
+		Limit(10)
+
+	sql, args, err := q.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "\nselect `name` as `n` \nfrom `users`\n"+
+		"where (age > ? and note <> '?')\n  and (`id` in (?,?))\n  and (type in (?,?))\n"+
+		"having (count(*) > ?)\nlimit 10\n", sql)
+	assert.Equal(t, []interface{}{18, 1, 2, "a", "b", 3}, args)
+
+	sql, args, err = q.UseDialect(PostgreSQL).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "\nselect \"name\" as \"n\" \nfrom \"users\"\n"+
+		"where (age > $1 and note <> '?')\n  and (\"id\" in ($2,$3))\n  and (type in ($4,$5))\n"+
+		"having (count(*) > $6)\nlimit 10\n", sql)
+	assert.Len(t, args, 6)
+
+	sql, err = q.String()
+	assert.Nil(t, err)
+	assert.Equal(t, "\nselect name as n \nfrom users\n"+
+		"where (age > 18 and note <> '?')\n  and (id in (1,2))\n  and (type in ('a','b'))\n"+
+		"having (count(*) > 3)\nlimit 10\n", sql)
+}
+
+func TestQueryQuoteCase(t *testing.T) {
+	q := (&Query{}).From("users").Select("name", "u.NAME", "userName")
+
+	sql, _, err := q.UseDialect(Oracle).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "\nselect name, u.\"NAME\", userName \nfrom users\n", sql)
+
+	sql, _, err = q.UseDialect(PostgreSQL).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "\nselect \"name\", \"u\".NAME, userName \nfrom \"users\"\n", sql)
+
+	sql, _, err = q.UseDialect(MySQL).Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "\nselect `name`, `u`.`NAME`, `userName` \nfrom `users`\n", sql)
+}
+
+func TestQueryWhereIn(t *testing.T) {
+	q := (&Query{}).From("users").Select("name").
+		WhereIn(map[string][]string{"name": {"a'b"}}).
+		WhereInArgs("id")
+
+	sql, args, err := q.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "\nselect `name` \nfrom `users`\nwhere (`name` in (?))\n  and (1 = 0)\n", sql)
+	assert.Equal(t, []interface{}{"a'b"}, args)
+}
+
+func TestQueryOrder(t *testing.T) {
+	q := (&Query{}).From("orders").
+		GroupBy("user_id", "day as d").
+		Agg("sum(amount) as amount", "count(*) as cnt").
+		WhereIn(map[string][]string{"status": {"paid"}, "channel": {"web"}})
+
+	for i := 0; i < 10; i++ {
+		sql, err := q.String()
+		assert.Nil(t, err)
+		assert.Equal(t, "\nselect user_id, day as d, sum(amount) as amount, count(*) as cnt \nfrom orders\n"+
+			"where (channel in ('web'))\n  and (status in ('paid'))\ngroup by user_id, day\n", sql)
+	}
+}
+
+func TestQueryJoinAndSubquery(t *testing.T) {
+	paid := (&Query{}).From("orders").Select("user_id").WhereArgs("status = ?", "paid")
+	recent := (&Query{}).From("logins").Select("user_id", "max(at) as at").GroupBy("user_id")
+
+	q := (&Query{}).With("paid", paid).
+		Distinct().
+		From("users as u").
+		Select("u.name", "l.at").
+		LeftJoinQuery(recent, "l", "l.user_id = u.id").
+		Join("vips v", "v.user_id = u.id and v.level > ?", 2).
+		WhereInArgs("u.id", (&Query{}).From("paid").Select("user_id")).
+		WhereArgs("u.age > ?", 18).
+		UnionAll((&Query{}).From("admins").Select("name", "at").WhereArgs("enabled = ?", true)).
+		UseDialect(PostgreSQL)
+
+	sql, args, err := q.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "\nwith \"paid\" as (select \"user_id\" \nfrom \"orders\"\nwhere (status = $1))\n"+
+		"select distinct \"u\".\"name\", \"l\".\"at\" \nfrom \"users\" as \"u\"\n"+
+		"left join (select \"user_id\", max(at) as \"at\" \nfrom \"logins\"\ngroup by \"user_id\") as \"l\" on l.user_id = u.id\n"+
+		"join \"vips\" \"v\" on v.user_id = u.id and v.level > $2\n"+
+		"where (\"u\".\"id\" in (select \"user_id\" \nfrom \"paid\"))\n  and (u.age > $3)\n"+
+		"union all\nselect \"name\", \"at\" \nfrom \"admins\"\nwhere (enabled = $4)\n", sql)
+	assert.Equal(t, []interface{}{"paid", 2, 18, true}, args)
+
+	_, _, err = (&Query{}).From("users").Select("name").FromQuery(&Query{}, "t").Build()
+	assert.Nil(t, err)
+	_, _, err = (&Query{}).FromQuery(&Query{}, "t").Select("name").Build()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,296 @@
+// 提供并发安全的分片 ConcurrentMap
+package generic
+
+import (
+	"fmt"
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/joker-circus/gotools/fastrand"
+)
+
+// DefaultShardCount 默认分片数
+const DefaultShardCount = 32
+
+type mapShard[K comparable, V any] struct {
+	sync.RWMutex
+	m map[K]V
+	_ [32]byte // 填充到 64 字节，避免相邻分片的伪共享
+}
+
+// ConcurrentMap 并发安全的分片 Map，key 按哈希分散到多个分片，每个分片各自加锁。
+type ConcurrentMap[K comparable, V any] struct {
+	shards []*mapShard[K, V]
+	mask   uint64
+	hasher func(K) uint64
+	count  atomic.Int64
+}
+
+// NewConcurrentMap 使用默认分片数创建 ConcurrentMap
+func NewConcurrentMap[K comparable, V any]() *ConcurrentMap[K, V] {
+	return NewConcurrentMapWithShards[K, V](DefaultShardCount, nil)
+}
+
+// NewConcurrentMapWithShards 指定分片数和哈希函数创建 ConcurrentMap。
+// shardCount 向上取整为 2 的幂，<= 0 时使用 DefaultShardCount；
+// hasher 为 nil 时使用默认哈希，支持底层类型为布尔、整数、浮点数、复数、字符串、指针及 chan 的 key，
+// 包括 type UserID int64 这样的命名类型；结构体、数组、接口等其他 key 必须传入 hasher，否则 panic。
+// hasher 对相等的 key 必须返回相同的哈希值。
+func NewConcurrentMapWithShards[K comparable, V any](shardCount int, hasher func(K) uint64) *ConcurrentMap[K, V] {
+	if shardCount <= 0 {
+		shardCount = DefaultShardCount
+	}
+	shardCount = 1 << bits.Len(uint(shardCount-1))
+	if hasher == nil {
+		hasher = newHasher[K]()
+	}
+
+	m := &ConcurrentMap[K, V]{
+		shards: make([]*mapShard[K, V], shardCount),
+		mask:   uint64(shardCount - 1),
+		hasher: hasher,
+	}
+	for i := range m.shards {
+		m.shards[i] = &mapShard[K, V]{m: make(map[K]V)}
+	}
+	return m
+}
+
+// newHasher 按 key 的底层类型返回带随机种子的默认哈希函数，key 按底层类型直接读取，不分配内存
+func newHasher[K comparable]() func(K) uint64 {
+	seed := maphash.MakeSeed()
+	intSeed := fastrand.Uint64()
+	mix := func(x uint64) uint64 {
+		// splitmix64 的混淆步骤
+		x ^= intSeed
+		x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+		x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+		return x ^ (x >> 31)
+	}
+	float := func(f float64) uint64 {
+		if f == 0 {
+			return mix(0) // 0 与 -0 相等
+		}
+		return mix(math.Float64bits(f))
+	}
+
+	t := reflect.TypeOf((*K)(nil)).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		return func(key K) uint64 { return maphash.String(seed, *(*string)(unsafe.Pointer(&key))) }
+	case reflect.Bool:
+		return func(key K) uint64 {
+			if *(*bool)(unsafe.Pointer(&key)) {
+				return mix(1)
+			}
+			return mix(0)
+		}
+	case reflect.Int:
+		return func(key K) uint64 { return mix(uint64(*(*int)(unsafe.Pointer(&key)))) }
+	case reflect.Int8:
+		return func(key K) uint64 { return mix(uint64(*(*int8)(unsafe.Pointer(&key)))) }
+	case reflect.Int16:
+		return func(key K) uint64 { return mix(uint64(*(*int16)(unsafe.Pointer(&key)))) }
+	case reflect.Int32:
+		return func(key K) uint64 { return mix(uint64(*(*int32)(unsafe.Pointer(&key)))) }
+	case reflect.Int64:
+		return func(key K) uint64 { return mix(uint64(*(*int64)(unsafe.Pointer(&key)))) }
+	case reflect.Uint:
+		return func(key K) uint64 { return mix(uint64(*(*uint)(unsafe.Pointer(&key)))) }
+	case reflect.Uint8:
+		return func(key K) uint64 { return mix(uint64(*(*uint8)(unsafe.Pointer(&key)))) }
+	case reflect.Uint16:
+		return func(key K) uint64 { return mix(uint64(*(*uint16)(unsafe.Pointer(&key)))) }
+	case reflect.Uint32:
+		return func(key K) uint64 { return mix(uint64(*(*uint32)(unsafe.Pointer(&key)))) }
+	case reflect.Uint64:
+		return func(key K) uint64 { return mix(*(*uint64)(unsafe.Pointer(&key))) }
+	case reflect.Uintptr, reflect.Pointer, reflect.UnsafePointer, reflect.Chan:
+		return func(key K) uint64 { return mix(uint64(*(*uintptr)(unsafe.Pointer(&key)))) }
+	case reflect.Float32:
+		return func(key K) uint64 { return float(float64(*(*float32)(unsafe.Pointer(&key)))) }
+	case reflect.Float64:
+		return func(key K) uint64 { return float(*(*float64)(unsafe.Pointer(&key))) }
+	case reflect.Complex64:
+		return func(key K) uint64 {
+			c := *(*complex64)(unsafe.Pointer(&key))
+			return float(float64(real(c))) ^ bits.RotateLeft64(float(float64(imag(c))), 32)
+		}
+	case reflect.Complex128:
+		return func(key K) uint64 {
+			c := *(*complex128)(unsafe.Pointer(&key))
+			return float(real(c)) ^ bits.RotateLeft64(float(imag(c)), 32)
+		}
+	}
+	panic(fmt.Sprintf("generic: ConcurrentMap requires a hasher for key type %v", t))
+}
+
+func (m *ConcurrentMap[K, V]) shard(key K) *mapShard[K, V] {
+	return m.shards[m.hasher(key)&m.mask]
+}
+
+// Get 获取 key 对应的值
+func (m *ConcurrentMap[K, V]) Get(key K) (V, bool) {
+	s := m.shard(key)
+	s.RLock()
+	v, ok := s.m[key]
+	s.RUnlock()
+	return v, ok
+}
+
+// Has 判断 key 是否存在
+func (m *ConcurrentMap[K, V]) Has(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// Set 设置 key 对应的值
+func (m *ConcurrentMap[K, V]) Set(key K, value V) {
+	s := m.shard(key)
+	s.Lock()
+	if _, ok := s.m[key]; !ok {
+		m.count.Add(1)
+	}
+	s.m[key] = value
+	s.Unlock()
+}
+
+// SetIfAbsent key 不存在时设置值，返回是否设置成功
+func (m *ConcurrentMap[K, V]) SetIfAbsent(key K, value V) bool {
+	s := m.shard(key)
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.m[key]; ok {
+		return false
+	}
+	s.m[key] = value
+	m.count.Add(1)
+	return true
+}
+
+// GetOrCompute 获取 key 对应的值，不存在时调用 f 计算并保存，loaded 表示值是否已存在。
+// 同一个 key 并发调用时 f 只会执行一次，f 执行期间持有分片的锁，不能在 f 中访问当前 Map。
+func (m *ConcurrentMap[K, V]) GetOrCompute(key K, f func() V) (value V, loaded bool) {
+	if v, ok := m.Get(key); ok {
+		return v, true
+	}
+
+	s := m.shard(key)
+	s.Lock()
+	defer s.Unlock()
+	if v, ok := s.m[key]; ok {
+		return v, true
+	}
+	value = f()
+	s.m[key] = value
+	m.count.Add(1)
+	return value, false
+}
+
+// Compute 原子地更新 key 对应的值，f 接收旧值及其是否存在，keep 为 false 时删除 key。
+// 返回新值及其是否保存，f 执行期间持有分片的锁，不能在 f 中访问当前 Map。
+func (m *ConcurrentMap[K, V]) Compute(key K, f func(old V, exists bool) (value V, keep bool)) (V, bool) {
+	s := m.shard(key)
+	s.Lock()
+	defer s.Unlock()
+
+	old, exists := s.m[key]
+	value, keep := f(old, exists)
+	switch {
+	case keep:
+		s.m[key] = value
+		if !exists {
+			m.count.Add(1)
+		}
+	case exists:
+		delete(s.m, key)
+		m.count.Add(-1)
+	}
+	return value, keep
+}
+
+// Delete 删除 key
+func (m *ConcurrentMap[K, V]) Delete(key K) {
+	m.LoadAndDelete(key)
+}
+
+// LoadAndDelete 删除 key，并返回删除前的值
+func (m *ConcurrentMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	s := m.shard(key)
+	s.Lock()
+	v, ok := s.m[key]
+	if ok {
+		delete(s.m, key)
+		m.count.Add(-1)
+	}
+	s.Unlock()
+	return v, ok
+}
+
+// Len 返回元素个数，不加锁
+func (m *ConcurrentMap[K, V]) Len() int {
+	return int(m.count.Load())
+}
+
+// Clear 删除全部元素
+func (m *ConcurrentMap[K, V]) Clear() {
+	for _, s := range m.shards {
+		s.Lock()
+		m.count.Add(-int64(len(s.m)))
+		s.m = make(map[K]V)
+		s.Unlock()
+	}
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// snapshot 逐个分片复制元素
+func (m *ConcurrentMap[K, V]) snapshot() []entry[K, V] {
+	entries := make([]entry[K, V], 0, m.Len())
+	for _, s := range m.shards {
+		s.RLock()
+		for k, v := range s.m {
+			entries = append(entries, entry[K, V]{k, v})
+		}
+		s.RUnlock()
+	}
+	return entries
+}
+
+// Range 遍历元素的快照，f 返回 false 时停止遍历。
+// 遍历时不持有锁，f 中可以修改当前 Map，修改不会反映到本次遍历中。
+func (m *ConcurrentMap[K, V]) Range(f func(key K, value V) bool) {
+	for _, e := range m.snapshot() {
+		if !f(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// Keys 返回全部 key
+func (m *ConcurrentMap[K, V]) Keys() []K {
+	entries := m.snapshot()
+	keys := make([]K, len(entries))
+	for i, e := range entries {
+		keys[i] = e.key
+	}
+	return keys
+}
+
+// Items 返回全部元素的副本
+func (m *ConcurrentMap[K, V]) Items() map[K]V {
+	entries := m.snapshot()
+	items := make(map[K]V, len(entries))
+	for _, e := range entries {
+		items[e.key] = e.value
+	}
+	return items
+}
@@ -0,0 +1,189 @@
+package generic
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentMap(t *testing.T) {
+	m := NewConcurrentMapWithShards[string, int](5, nil)
+	assert.Equal(t, 8, len(m.shards))
+
+	m.Set("a", 1)
+	m.Set("a", 2)
+	assert.True(t, m.SetIfAbsent("b", 3))
+	assert.False(t, m.SetIfAbsent("b", 4))
+	assert.Equal(t, 2, m.Len())
+
+	v, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	assert.False(t, m.Has("c"))
+
+	v, loaded := m.GetOrCompute("c", func() int { return 5 })
+	assert.False(t, loaded)
+	assert.Equal(t, 5, v)
+	v, loaded = m.GetOrCompute("c", func() int { return 6 })
+	assert.True(t, loaded)
+	assert.Equal(t, 5, v)
+
+	incr := func(old int, exists bool) (int, bool) { return old + 1, true }
+	v, _ = m.Compute("a", incr)
+	assert.Equal(t, 3, v)
+	v, _ = m.Compute("d", incr)
+	assert.Equal(t, 1, v)
+	_, kept := m.Compute("d", func(int, bool) (int, bool) { return 0, false })
+	assert.False(t, kept)
+	assert.False(t, m.Has("d"))
+
+	v, ok = m.LoadAndDelete("b")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	assert.Equal(t, map[string]int{"a": 3, "c": 5}, m.Items())
+	assert.Equal(t, 2, m.Len())
+
+	m.Clear()
+	assert.Equal(t, 0, m.Len())
+	assert.Empty(t, m.Keys())
+}
+
+func TestConcurrentMapRangeSnapshot(t *testing.T) {
+	m := NewConcurrentMap[int, int]()
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+
+	n := 0
+	m.Range(func(key, value int) bool {
+		m.Delete(key)
+		m.Set(key+1000, value)
+		n++
+		return true
+	})
+	assert.Equal(t, 100, n)
+	assert.Equal(t, 100, m.Len())
+	assert.False(t, m.Has(0))
+}
+
+func TestConcurrentMapParallel(t *testing.T) {
+	m := NewConcurrentMap[string, int]()
+	var calls atomic.Int64
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := strconv.Itoa(i % 100)
+				m.GetOrCompute(key, func() int {
+					calls.Add(1)
+					return 0
+				})
+				m.Compute(key, func(old int, _ bool) (int, bool) { return old + 1, true })
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(100), calls.Load())
+	assert.Equal(t, 100, m.Len())
+	sum := 0
+	m.Range(func(_ string, v int) bool {
+		sum += v
+		return true
+	})
+	assert.Equal(t, 8000, sum)
+}
+
+type point struct{ X, Y int }
+
+func TestConcurrentMapHasher(t *testing.T) {
+	m := NewConcurrentMapWithShards[point, string](4, func(p point) uint64 {
+		return uint64(p.X*31 + p.Y)
+	})
+	m.Set(point{1, 2}, "a")
+	v, ok := m.Get(point{1, 2})
+	assert.True(t, ok)
+	assert.Equal(t, "a", v)
+
+	// 默认哈希中 0 与 -0 相等
+	f := NewConcurrentMap[float64, int]()
+	f.Set(0, 1)
+	assert.True(t, f.Has(math.Copysign(0, -1)))
+
+	// 结构体等组合类型的 key 必须传入 hasher
+	assert.Panics(t, func() { NewConcurrentMap[point, string]() })
+	assert.Panics(t, func() { NewConcurrentMap[any, string]() })
+}
+
+type testUserID int64
+
+type testStatus string
+
+// String 对相等的 key 返回不同的结果，默认哈希不能依赖它
+func (s testStatus) String() string {
+	return strconv.Itoa(rand.Int())
+}
+
+func TestConcurrentMapNamedKey(t *testing.T) {
+	ids := NewConcurrentMapWithShards[testUserID, int](64, nil)
+	statuses := NewConcurrentMapWithShards[testStatus, int](64, nil)
+	for i := 0; i < 100; i++ {
+		ids.Set(testUserID(i), i)
+		statuses.Set(testStatus(strconv.Itoa(i)), i)
+	}
+	for i := 0; i < 100; i++ {
+		assert.True(t, ids.Has(testUserID(i)))
+		assert.True(t, statuses.Has(testStatus(strconv.Itoa(i))))
+	}
+
+	key := testUserID(1 << 40)
+	allocs := testing.AllocsPerRun(100, func() {
+		ids.Set(key, 1)
+		ids.Get(key)
+	})
+	assert.Equal(t, float64(0), allocs)
+
+	ptrs := NewConcurrentMap[*point, int]()
+	p := &point{}
+	ptrs.Set(p, 1)
+	assert.True(t, ptrs.Has(p))
+	assert.False(t, ptrs.Has(&point{}))
+}
+
+func TestConcurrentSet(t *testing.T) {
+	s := NewConcurrentSet(1, 2, 3)
+	assert.True(t, s.Contains(1))
+	assert.True(t, s.AddIfAbsent(4))
+	assert.False(t, s.AddIfAbsent(4))
+	s.Remove(1)
+	assert.Equal(t, 3, s.Len())
+
+	items := s.Items()
+	sort.Ints(items)
+	assert.Equal(t, []int{2, 3, 4}, items)
+	assert.True(t, s.ToSet().Equals(NewSet(2, 3, 4)))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s.Add(g*100 + i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	assert.Equal(t, 400, s.Len())
+
+	s.Clear()
+	assert.Equal(t, 0, s.Len())
+}
@@ -0,0 +1,74 @@
+// 提供并发安全的分片 ConcurrentSet
+package generic
+
+// ConcurrentSet 基于 ConcurrentMap 的并发安全 Set
+type ConcurrentSet[T comparable] struct {
+	m *ConcurrentMap[T, EmptyType]
+}
+
+// NewConcurrentSet 使用默认分片数创建 ConcurrentSet
+func NewConcurrentSet[T comparable](items ...T) *ConcurrentSet[T] {
+	return NewConcurrentSetWithShards[T](DefaultShardCount, nil, items...)
+}
+
+// NewConcurrentSetWithShards 指定分片数和哈希函数创建 ConcurrentSet，参数同 NewConcurrentMapWithShards
+func NewConcurrentSetWithShards[T comparable](shardCount int, hasher func(T) uint64, items ...T) *ConcurrentSet[T] {
+	s := &ConcurrentSet[T]{
+		m: NewConcurrentMapWithShards[T, EmptyType](shardCount, hasher),
+	}
+	s.Add(items...)
+	return s
+}
+
+// Add 添加元素
+func (s *ConcurrentSet[T]) Add(items ...T) *ConcurrentSet[T] {
+	for _, v := range items {
+		s.m.Set(v, empty)
+	}
+	return s
+}
+
+// AddIfAbsent 元素不存在时添加，返回是否添加成功
+func (s *ConcurrentSet[T]) AddIfAbsent(item T) bool {
+	return s.m.SetIfAbsent(item, empty)
+}
+
+// Remove 删除元素
+func (s *ConcurrentSet[T]) Remove(items ...T) *ConcurrentSet[T] {
+	for _, v := range items {
+		s.m.Delete(v)
+	}
+	return s
+}
+
+// Contains 判断元素是否存在
+func (s *ConcurrentSet[T]) Contains(item T) bool {
+	return s.m.Has(item)
+}
+
+// Len 返回元素个数，不加锁
+func (s *ConcurrentSet[T]) Len() int {
+	return s.m.Len()
+}
+
+// Clear 删除全部元素
+func (s *ConcurrentSet[T]) Clear() {
+	s.m.Clear()
+}
+
+// Range 遍历元素的快照，f 返回 false 时停止遍历
+func (s *ConcurrentSet[T]) Range(f func(item T) bool) {
+	s.m.Range(func(key T, _ EmptyType) bool {
+		return f(key)
+	})
+}
+
+// Items 返回全部元素
+func (s *ConcurrentSet[T]) Items() []T {
+	return s.m.Keys()
+}
+
+// ToSet 返回非并发安全的 Set 副本
+func (s *ConcurrentSet[T]) ToSet() *Set[T] {
+	return NewSet(s.Items()...)
+}
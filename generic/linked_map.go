@@ -0,0 +1,141 @@
+// 提供非并发安全的按插入顺序遍历的 LinkedMap
+package generic
+
+type linkedEntry[K comparable, V any] struct {
+	key        K
+	value      V
+	prev, next *linkedEntry[K, V]
+}
+
+// LinkedMap 按插入顺序遍历的 Map，更新已存在的 key 不改变其顺序。零值可以直接使用。
+type LinkedMap[K comparable, V any] struct {
+	m          map[K]*linkedEntry[K, V]
+	head, tail *linkedEntry[K, V]
+}
+
+func NewLinkedMap[K comparable, V any]() *LinkedMap[K, V] {
+	return &LinkedMap[K, V]{m: make(map[K]*linkedEntry[K, V])}
+}
+
+// Set 设置 key 对应的值，key 不存在时添加到末尾
+func (m *LinkedMap[K, V]) Set(key K, value V) {
+	if e, ok := m.m[key]; ok {
+		e.value = value
+		return
+	}
+	if m.m == nil {
+		m.m = make(map[K]*linkedEntry[K, V])
+	}
+
+	e := &linkedEntry[K, V]{key: key, value: value, prev: m.tail}
+	if m.tail == nil {
+		m.head = e
+	} else {
+		m.tail.next = e
+	}
+	m.tail = e
+	m.m[key] = e
+}
+
+// Get 获取 key 对应的值
+func (m *LinkedMap[K, V]) Get(key K) (V, bool) {
+	if e, ok := m.m[key]; ok {
+		return e.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Has 判断 key 是否存在
+func (m *LinkedMap[K, V]) Has(key K) bool {
+	_, ok := m.m[key]
+	return ok
+}
+
+// Delete 删除 key，返回 key 是否存在
+func (m *LinkedMap[K, V]) Delete(key K) bool {
+	e, ok := m.m[key]
+	if !ok {
+		return false
+	}
+	delete(m.m, key)
+	if e.prev == nil {
+		m.head = e.next
+	} else {
+		e.prev.next = e.next
+	}
+	if e.next == nil {
+		m.tail = e.prev
+	} else {
+		e.next.prev = e.prev
+	}
+	return true
+}
+
+// Len 返回元素个数
+func (m *LinkedMap[K, V]) Len() int {
+	return len(m.m)
+}
+
+// Clear 删除全部元素
+func (m *LinkedMap[K, V]) Clear() {
+	m.m = make(map[K]*linkedEntry[K, V])
+	m.head, m.tail = nil, nil
+}
+
+// First 返回最早插入的元素
+func (m *LinkedMap[K, V]) First() (K, V, bool) {
+	return m.entry(m.head)
+}
+
+// Last 返回最晚插入的元素
+func (m *LinkedMap[K, V]) Last() (K, V, bool) {
+	return m.entry(m.tail)
+}
+
+func (m *LinkedMap[K, V]) entry(e *linkedEntry[K, V]) (key K, value V, ok bool) {
+	if e == nil {
+		return
+	}
+	return e.key, e.value, true
+}
+
+// Range 按插入顺序遍历，f 返回 false 时停止遍历
+func (m *LinkedMap[K, V]) Range(f func(key K, value V) bool) {
+	for e := m.head; e != nil; {
+		next := e.next // 允许在 f 中删除当前元素
+		if !f(e.key, e.value) {
+			return
+		}
+		e = next
+	}
+}
+
+// Keys 按插入顺序返回全部 key
+func (m *LinkedMap[K, V]) Keys() []K {
+	keys := make([]K, 0, m.Len())
+	for e := m.head; e != nil; e = e.next {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Values 按插入顺序返回全部值
+func (m *LinkedMap[K, V]) Values() []V {
+	values := make([]V, 0, m.Len())
+	for e := m.head; e != nil; e = e.next {
+		values = append(values, e.value)
+	}
+	return values
+}
+
+// MarshalJSON 按插入顺序编码为 JSON 对象
+func (m *LinkedMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalMap[K, V](m.Range)
+}
+
+// UnmarshalJSON 按 JSON 对象中的顺序解码，替换原有元素
+func (m *LinkedMap[K, V]) UnmarshalJSON(data []byte) error {
+	m.Clear()
+	return unmarshalMap[K, V](data, m.Set)
+}
@@ -1,7 +1,11 @@
 // 提供非并发安全的可排序 OrderSet
 package generic
 
-import "sort"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"sort"
+)
 
 type OrderSet[T Ordered] struct {
 	*Set[T]
@@ -23,3 +27,33 @@ func (s *OrderSet[T]) SortItems() []T {
 	})
 	return slice
 }
+
+// MarshalJSON 编码为升序排列的 JSON 数组
+func (s *OrderSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.SortItems())
+}
+
+// UnmarshalJSON 由 JSON 数组解码，替换原有元素
+func (s *OrderSet[T]) UnmarshalJSON(data []byte) error {
+	if s.Set == nil {
+		s.Set = NewSet[T]()
+	}
+	return s.Set.UnmarshalJSON(data)
+}
+
+// Value 存储为升序排列的 JSON 数组
+func (s *OrderSet[T]) Value() (driver.Value, error) {
+	if s == nil || s.Set == nil {
+		return nil, nil
+	}
+	b, err := s.MarshalJSON()
+	return string(b), err
+}
+
+// Scan 由 JSON 数组读取，NULL 为空集合
+func (s *OrderSet[T]) Scan(v interface{}) error {
+	if s.Set == nil {
+		s.Set = NewSet[T]()
+	}
+	return s.Set.Scan(v)
+}
@@ -1,6 +1,12 @@
 // 提供非并发安全的 Set
 package generic
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
 type EmptyType struct{}
 
 var empty EmptyType
@@ -88,3 +94,132 @@ func (s *Set[T]) Equals(other *Set[T]) bool {
 func (s *Set[T]) Len() int {
 	return len(s.m)
 }
+
+// Clone 返回集合的副本
+func (s *Set[T]) Clone() *Set[T] {
+	c := &Set[T]{m: make(map[T]EmptyType, len(s.m))}
+	for k := range s.m {
+		c.m[k] = empty
+	}
+	return c
+}
+
+// Union 返回两个集合的并集
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	c := s.Clone()
+	c.Merge(other)
+	return c
+}
+
+// Intersect 返回两个集合的交集
+func (s *Set[T]) Intersect(other *Set[T]) *Set[T] {
+	small, large := s, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+
+	c := NewSet[T]()
+	for k := range small.m {
+		if _, ok := large.m[k]; ok {
+			c.m[k] = empty
+		}
+	}
+	return c
+}
+
+// Difference 返回在 s 中但不在 other 中的元素
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	return s.Filter(func(item T) bool {
+		_, ok := other.m[item]
+		return !ok
+	})
+}
+
+// SymmetricDifference 返回只在其中一个集合中的元素
+func (s *Set[T]) SymmetricDifference(other *Set[T]) *Set[T] {
+	c := s.Difference(other)
+	for k := range other.m {
+		if _, ok := s.m[k]; !ok {
+			c.m[k] = empty
+		}
+	}
+	return c
+}
+
+// IsSubset 判断 s 是否为 other 的子集
+func (s *Set[T]) IsSubset(other *Set[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	for k := range s.m {
+		if _, ok := other.m[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset 判断 s 是否为 other 的超集
+func (s *Set[T]) IsSuperset(other *Set[T]) bool {
+	return other.IsSubset(s)
+}
+
+// Pop 删除并返回任意一个元素，集合为空时返回 false
+func (s *Set[T]) Pop() (T, bool) {
+	for k := range s.m {
+		delete(s.m, k)
+		return k, true
+	}
+	var zero T
+	return zero, false
+}
+
+// Filter 返回 f 为 true 的元素组成的集合
+func (s *Set[T]) Filter(f func(item T) bool) *Set[T] {
+	c := NewSet[T]()
+	for k := range s.m {
+		if f(k) {
+			c.m[k] = empty
+		}
+	}
+	return c
+}
+
+// MarshalJSON 编码为 JSON 数组，元素顺序不固定，需要固定顺序时使用 OrderSet
+func (s *Set[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Items())
+}
+
+// UnmarshalJSON 由 JSON 数组解码，替换原有元素
+func (s *Set[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	*s = *NewSet[T](items...)
+	return nil
+}
+
+// Value 存储为 JSON 数组
+func (s *Set[T]) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	b, err := s.MarshalJSON()
+	return string(b), err
+}
+
+// Scan 由 JSON 数组读取，NULL 为空集合
+func (s *Set[T]) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		*s = *NewSet[T]()
+		return nil
+	case []byte:
+		return s.UnmarshalJSON(value)
+	case string:
+		return s.UnmarshalJSON([]byte(value))
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to %T", v, v, s)
+	}
+}
@@ -1,6 +1,7 @@
 package generic
 
 import (
+	"encoding/json"
 	"sort"
 	"strings"
 	"testing"
@@ -40,3 +41,40 @@ func TestStringSet(t *testing.T) {
 	sort.Strings(slice)
 	assert.True(t, strings.Join(slice, "") == a+b+c)
 }
+
+func TestSetAlgebra(t *testing.T) {
+	a, b := NewOrderSet(1, 2, 3), NewOrderSet(3, 4)
+
+	assert.ElementsMatch(t, []int{1, 2, 3, 4}, a.Union(b.Set).Items())
+	assert.ElementsMatch(t, []int{3}, a.Intersect(b.Set).Items())
+	assert.ElementsMatch(t, []int{1, 2}, a.Difference(b.Set).Items())
+	assert.ElementsMatch(t, []int{1, 2, 4}, a.SymmetricDifference(b.Set).Items())
+	assert.True(t, NewSet(1, 2).IsSubset(a.Set))
+	assert.False(t, a.IsSuperset(b.Set))
+	assert.ElementsMatch(t, []int{1, 3}, a.Filter(func(v int) bool { return v%2 == 1 }).Items())
+
+	c := a.Clone()
+	v, ok := c.Pop()
+	assert.True(t, ok)
+	assert.False(t, c.Contains(v))
+	assert.Equal(t, 3, a.Len())
+
+	data, err := json.Marshal(a)
+	assert.Nil(t, err)
+	assert.Equal(t, "[1,2,3]", string(data))
+
+	var decoded struct {
+		Tags *OrderSet[string] `json:"tags"`
+		IDs  *Set[int]         `json:"ids"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"tags":["b","a"],"ids":[1,1]}`), &decoded))
+	assert.Equal(t, []string{"a", "b"}, decoded.Tags.SortItems())
+	assert.Equal(t, []int{1}, decoded.IDs.Items())
+
+	value, err := decoded.Tags.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, `["a","b"]`, value)
+	var scanned Set[string]
+	assert.Nil(t, scanned.Scan(value))
+	assert.True(t, scanned.Equals(decoded.Tags.Set))
+}
@@ -0,0 +1,121 @@
+// 提供非并发安全的有序 SortedSet
+package generic
+
+import "encoding/json"
+
+// SortedSet 基于 TreeMap、元素始终按升序排列的 Set。零值可以直接使用，浮点数元素不能为 NaN。
+type SortedSet[T Ordered] struct {
+	t TreeMap[T, EmptyType]
+}
+
+func NewSortedSet[T Ordered](items ...T) *SortedSet[T] {
+	s := &SortedSet[T]{}
+	s.Add(items...)
+	return s
+}
+
+// Add 添加元素
+func (s *SortedSet[T]) Add(items ...T) *SortedSet[T] {
+	for _, v := range items {
+		s.t.Put(v, empty)
+	}
+	return s
+}
+
+// Remove 删除元素
+func (s *SortedSet[T]) Remove(items ...T) *SortedSet[T] {
+	for _, v := range items {
+		s.t.Delete(v)
+	}
+	return s
+}
+
+// Contains 判断元素是否存在
+func (s *SortedSet[T]) Contains(item T) bool {
+	return s.t.Has(item)
+}
+
+// Len 返回元素个数
+func (s *SortedSet[T]) Len() int {
+	return s.t.Len()
+}
+
+// Clear 删除全部元素
+func (s *SortedSet[T]) Clear() {
+	s.t.Clear()
+}
+
+// Items 按升序返回全部元素
+func (s *SortedSet[T]) Items() []T {
+	return s.t.Keys()
+}
+
+// Min 返回最小的元素
+func (s *SortedSet[T]) Min() (T, bool) {
+	item, _, ok := s.t.Min()
+	return item, ok
+}
+
+// Max 返回最大的元素
+func (s *SortedSet[T]) Max() (T, bool) {
+	item, _, ok := s.t.Max()
+	return item, ok
+}
+
+// Floor 返回小于等于 item 的最大元素
+func (s *SortedSet[T]) Floor(item T) (T, bool) {
+	v, _, ok := s.t.Floor(item)
+	return v, ok
+}
+
+// Ceiling 返回大于等于 item 的最小元素
+func (s *SortedSet[T]) Ceiling(item T) (T, bool) {
+	v, _, ok := s.t.Ceiling(item)
+	return v, ok
+}
+
+// Rank 返回小于 item 的元素个数
+func (s *SortedSet[T]) Rank(item T) int {
+	return s.t.Rank(item)
+}
+
+// Select 返回按升序排列下标为 i 的元素
+func (s *SortedSet[T]) Select(i int) (T, bool) {
+	v, _, ok := s.t.Select(i)
+	return v, ok
+}
+
+// Range 按升序遍历，f 返回 false 时停止遍历，遍历时不能修改 SortedSet
+func (s *SortedSet[T]) Range(f func(item T) bool) {
+	s.t.Range(func(key T, _ EmptyType) bool {
+		return f(key)
+	})
+}
+
+// RangeBetween 按升序遍历 [from, to) 区间的元素，f 返回 false 时停止遍历
+func (s *SortedSet[T]) RangeBetween(from, to T, f func(item T) bool) {
+	s.t.RangeBetween(from, to, func(key T, _ EmptyType) bool {
+		return f(key)
+	})
+}
+
+// ToSet 返回非并发安全的 Set 副本
+func (s *SortedSet[T]) ToSet() *Set[T] {
+	return NewSet(s.Items()...)
+}
+
+// MarshalJSON 编码为升序排列的 JSON 数组
+func (s *SortedSet[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Items())
+}
+
+// UnmarshalJSON 由 JSON 数组解码，替换原有元素
+func (s *SortedSet[T]) UnmarshalJSON(data []byte) error {
+	var items []T
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	s.Clear()
+	s.Add(items...)
+	return nil
+}
@@ -0,0 +1,474 @@
+// 提供非并发安全的按 key 排序的 TreeMap
+package generic
+
+type treeNode[K Ordered, V any] struct {
+	key                 K
+	value               V
+	left, right, parent *treeNode[K, V]
+	red                 bool
+	size                int // 子树的节点数，用于排名查询
+}
+
+// TreeMap 基于红黑树、按 key 升序排列的 Map，支持 Floor、Ceiling、区间遍历和排名查询。
+// 零值可以直接使用，浮点数 key 不能为 NaN。
+type TreeMap[K Ordered, V any] struct {
+	root *treeNode[K, V]
+	leaf *treeNode[K, V] // 哨兵叶子节点，黑色且 size 为 0
+}
+
+func NewTreeMap[K Ordered, V any]() *TreeMap[K, V] {
+	t := &TreeMap[K, V]{}
+	t.init()
+	return t
+}
+
+func (t *TreeMap[K, V]) init() {
+	if t.leaf == nil {
+		t.leaf = &treeNode[K, V]{}
+		t.root = t.leaf
+	}
+}
+
+// Put 设置 key 对应的值
+func (t *TreeMap[K, V]) Put(key K, value V) {
+	if n := t.find(key); n != t.leaf {
+		n.value = value
+		return
+	}
+	t.init()
+
+	parent := t.leaf
+	for x := t.root; x != t.leaf; {
+		parent = x
+		x.size++
+		if key < x.key {
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+
+	n := &treeNode[K, V]{key: key, value: value, left: t.leaf, right: t.leaf, parent: parent, red: true, size: 1}
+	switch {
+	case parent == t.leaf:
+		t.root = n
+	case key < parent.key:
+		parent.left = n
+	default:
+		parent.right = n
+	}
+	t.insertFixup(n)
+}
+
+// Get 获取 key 对应的值
+func (t *TreeMap[K, V]) Get(key K) (V, bool) {
+	if n := t.find(key); n != t.leaf {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Has 判断 key 是否存在
+func (t *TreeMap[K, V]) Has(key K) bool {
+	return t.find(key) != t.leaf
+}
+
+// Delete 删除 key，返回 key 是否存在
+func (t *TreeMap[K, V]) Delete(key K) bool {
+	z := t.find(key)
+	if z == t.leaf {
+		return false
+	}
+
+	y := z
+	if z.left != t.leaf && z.right != t.leaf {
+		y = t.minimum(z.right)
+	}
+	for p := y.parent; p != t.leaf; p = p.parent {
+		p.size--
+	}
+
+	var x *treeNode[K, V]
+	yRed := y.red
+	switch {
+	case z.left == t.leaf:
+		x = z.right
+		t.transplant(z, z.right)
+	case z.right == t.leaf:
+		x = z.left
+		t.transplant(z, z.left)
+	default:
+		x = y.right
+		if y.parent == z {
+			x.parent = y
+		} else {
+			t.transplant(y, y.right)
+			y.right = z.right
+			y.right.parent = y
+		}
+		t.transplant(z, y)
+		y.left = z.left
+		y.left.parent = y
+		y.red = z.red
+		y.size = z.size
+	}
+	if !yRed {
+		t.deleteFixup(x)
+	}
+	return true
+}
+
+// Len 返回元素个数
+func (t *TreeMap[K, V]) Len() int {
+	if t.root == t.leaf {
+		return 0
+	}
+	return t.root.size
+}
+
+// Clear 删除全部元素
+func (t *TreeMap[K, V]) Clear() {
+	t.root = t.leaf
+}
+
+// Min 返回最小的 key
+func (t *TreeMap[K, V]) Min() (K, V, bool) {
+	if t.root == t.leaf {
+		return t.entry(t.leaf)
+	}
+	return t.entry(t.minimum(t.root))
+}
+
+// Max 返回最大的 key
+func (t *TreeMap[K, V]) Max() (K, V, bool) {
+	x := t.root
+	if x == t.leaf {
+		return t.entry(t.leaf)
+	}
+	for x.right != t.leaf {
+		x = x.right
+	}
+	return t.entry(x)
+}
+
+// Floor 返回小于等于 key 的最大 key
+func (t *TreeMap[K, V]) Floor(key K) (K, V, bool) {
+	return t.entry(t.floor(key, true))
+}
+
+// Ceiling 返回大于等于 key 的最小 key
+func (t *TreeMap[K, V]) Ceiling(key K) (K, V, bool) {
+	return t.entry(t.ceiling(key, true))
+}
+
+// Lower 返回小于 key 的最大 key
+func (t *TreeMap[K, V]) Lower(key K) (K, V, bool) {
+	return t.entry(t.floor(key, false))
+}
+
+// Higher 返回大于 key 的最小 key
+func (t *TreeMap[K, V]) Higher(key K) (K, V, bool) {
+	return t.entry(t.ceiling(key, false))
+}
+
+// Rank 返回小于 key 的元素个数，即 key 按升序排列的下标
+func (t *TreeMap[K, V]) Rank(key K) int {
+	rank := 0
+	for x := t.root; x != t.leaf; {
+		if key <= x.key {
+			x = x.left
+		} else {
+			rank += x.left.size + 1
+			x = x.right
+		}
+	}
+	return rank
+}
+
+// Select 返回按升序排列下标为 i 的元素，i 从 0 开始
+func (t *TreeMap[K, V]) Select(i int) (K, V, bool) {
+	if i < 0 {
+		return t.entry(t.leaf)
+	}
+	for x := t.root; x != t.leaf; {
+		switch l := x.left.size; {
+		case i < l:
+			x = x.left
+		case i == l:
+			return t.entry(x)
+		default:
+			i -= l + 1
+			x = x.right
+		}
+	}
+	return t.entry(t.leaf)
+}
+
+// Range 按 key 升序遍历，f 返回 false 时停止遍历，遍历时不能修改 TreeMap
+func (t *TreeMap[K, V]) Range(f func(key K, value V) bool) {
+	if t.root == t.leaf {
+		return
+	}
+	for x := t.minimum(t.root); x != t.leaf; x = t.successor(x) {
+		if !f(x.key, x.value) {
+			return
+		}
+	}
+}
+
+// RangeBetween 按 key 升序遍历 [from, to) 区间，f 返回 false 时停止遍历，遍历时不能修改 TreeMap
+func (t *TreeMap[K, V]) RangeBetween(from, to K, f func(key K, value V) bool) {
+	for x := t.ceiling(from, true); x != t.leaf && x.key < to; x = t.successor(x) {
+		if !f(x.key, x.value) {
+			return
+		}
+	}
+}
+
+// RangeDesc 按 key 降序遍历，f 返回 false 时停止遍历，遍历时不能修改 TreeMap
+func (t *TreeMap[K, V]) RangeDesc(f func(key K, value V) bool) {
+	x := t.root
+	if x == t.leaf {
+		return
+	}
+	for x.right != t.leaf {
+		x = x.right
+	}
+	for ; x != t.leaf; x = t.predecessor(x) {
+		if !f(x.key, x.value) {
+			return
+		}
+	}
+}
+
+// Keys 按升序返回全部 key
+func (t *TreeMap[K, V]) Keys() []K {
+	keys := make([]K, 0, t.Len())
+	t.Range(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Values 按 key 升序返回全部值
+func (t *TreeMap[K, V]) Values() []V {
+	values := make([]V, 0, t.Len())
+	t.Range(func(_ K, value V) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// MarshalJSON 按 key 升序编码为 JSON 对象
+func (t *TreeMap[K, V]) MarshalJSON() ([]byte, error) {
+	return marshalMap[K, V](t.Range)
+}
+
+// UnmarshalJSON 由 JSON 对象解码，替换原有元素
+func (t *TreeMap[K, V]) UnmarshalJSON(data []byte) error {
+	t.Clear()
+	return unmarshalMap[K, V](data, t.Put)
+}
+
+func (t *TreeMap[K, V]) entry(n *treeNode[K, V]) (key K, value V, ok bool) {
+	if n == t.leaf {
+		return
+	}
+	return n.key, n.value, true
+}
+
+func (t *TreeMap[K, V]) find(key K) *treeNode[K, V] {
+	x := t.root
+	for x != t.leaf && x.key != key {
+		if key < x.key {
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+	return x
+}
+
+// floor 返回小于等于（inclusive 为 false 时小于）key 的最大节点
+func (t *TreeMap[K, V]) floor(key K, inclusive bool) *treeNode[K, V] {
+	best := t.leaf
+	for x := t.root; x != t.leaf; {
+		if x.key < key || inclusive && x.key == key {
+			best = x
+			x = x.right
+		} else {
+			x = x.left
+		}
+	}
+	return best
+}
+
+// ceiling 返回大于等于（inclusive 为 false 时大于）key 的最小节点
+func (t *TreeMap[K, V]) ceiling(key K, inclusive bool) *treeNode[K, V] {
+	best := t.leaf
+	for x := t.root; x != t.leaf; {
+		if x.key > key || inclusive && x.key == key {
+			best = x
+			x = x.left
+		} else {
+			x = x.right
+		}
+	}
+	return best
+}
+
+func (t *TreeMap[K, V]) minimum(x *treeNode[K, V]) *treeNode[K, V] {
+	for x.left != t.leaf {
+		x = x.left
+	}
+	return x
+}
+
+func (t *TreeMap[K, V]) successor(x *treeNode[K, V]) *treeNode[K, V] {
+	if x.right != t.leaf {
+		return t.minimum(x.right)
+	}
+	p := x.parent
+	for p != t.leaf && x == p.right {
+		x, p = p, p.parent
+	}
+	return p
+}
+
+func (t *TreeMap[K, V]) predecessor(x *treeNode[K, V]) *treeNode[K, V] {
+	if x.left != t.leaf {
+		x = x.left
+		for x.right != t.leaf {
+			x = x.right
+		}
+		return x
+	}
+	p := x.parent
+	for p != t.leaf && x == p.left {
+		x, p = p, p.parent
+	}
+	return p
+}
+
+func (t *TreeMap[K, V]) rotateLeft(x *treeNode[K, V]) {
+	y := x.right
+	x.right = y.left
+	if y.left != t.leaf {
+		y.left.parent = x
+	}
+	t.transplant(x, y)
+	y.left = x
+	x.parent = y
+
+	y.size = x.size
+	x.size = x.left.size + x.right.size + 1
+}
+
+func (t *TreeMap[K, V]) rotateRight(x *treeNode[K, V]) {
+	y := x.left
+	x.left = y.right
+	if y.right != t.leaf {
+		y.right.parent = x
+	}
+	t.transplant(x, y)
+	y.right = x
+	x.parent = y
+
+	y.size = x.size
+	x.size = x.left.size + x.right.size + 1
+}
+
+// transplant 用 v 替换 u 在父节点中的位置
+func (t *TreeMap[K, V]) transplant(u, v *treeNode[K, V]) {
+	switch {
+	case u.parent == t.leaf:
+		t.root = v
+	case u == u.parent.left:
+		u.parent.left = v
+	default:
+		u.parent.right = v
+	}
+	v.parent = u.parent
+}
+
+func (t *TreeMap[K, V]) insertFixup(z *treeNode[K, V]) {
+	for z.parent.red {
+		gp := z.parent.parent
+		if z.parent == gp.left {
+			if uncle := gp.right; uncle.red {
+				z.parent.red, uncle.red, gp.red = false, false, true
+				z = gp
+				continue
+			}
+			if z == z.parent.right {
+				z = z.parent
+				t.rotateLeft(z)
+			}
+			z.parent.red, gp.red = false, true
+			t.rotateRight(gp)
+		} else {
+			if uncle := gp.left; uncle.red {
+				z.parent.red, uncle.red, gp.red = false, false, true
+				z = gp
+				continue
+			}
+			if z == z.parent.left {
+				z = z.parent
+				t.rotateRight(z)
+			}
+			z.parent.red, gp.red = false, true
+			t.rotateLeft(gp)
+		}
+	}
+	t.root.red = false
+}
+
+func (t *TreeMap[K, V]) deleteFixup(x *treeNode[K, V]) {
+	for x != t.root && !x.red {
+		if x == x.parent.left {
+			w := x.parent.right
+			if w.red {
+				w.red, x.parent.red = false, true
+				t.rotateLeft(x.parent)
+				w = x.parent.right
+			}
+			if !w.left.red && !w.right.red {
+				w.red = true
+				x = x.parent
+				continue
+			}
+			if !w.right.red {
+				w.left.red, w.red = false, true
+				t.rotateRight(w)
+				w = x.parent.right
+			}
+			w.red, x.parent.red, w.right.red = x.parent.red, false, false
+			t.rotateLeft(x.parent)
+			x = t.root
+		} else {
+			w := x.parent.left
+			if w.red {
+				w.red, x.parent.red = false, true
+				t.rotateRight(x.parent)
+				w = x.parent.left
+			}
+			if !w.left.red && !w.right.red {
+				w.red = true
+				x = x.parent
+				continue
+			}
+			if !w.left.red {
+				w.right.red, w.red = false, true
+				t.rotateLeft(w)
+				w = x.parent.left
+			}
+			w.red, x.parent.red, w.left.red = x.parent.red, false, false
+			t.rotateRight(x.parent)
+			x = t.root
+		}
+	}
+	x.red = false
+}
@@ -0,0 +1,226 @@
+package generic
+
+import (
+	"encoding/json"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// checkTree 校验红黑树性质和子树大小，返回黑高
+func checkTree[K Ordered, V any](t *testing.T, m *TreeMap[K, V], n *treeNode[K, V]) int {
+	if n == m.leaf {
+		assert.Equal(t, 0, n.size)
+		return 1
+	}
+	if n.red {
+		assert.False(t, n.left.red || n.right.red, "red node has red child")
+	}
+	if n.left != m.leaf {
+		assert.Less(t, n.left.key, n.key)
+		assert.Equal(t, n, n.left.parent)
+	}
+	if n.right != m.leaf {
+		assert.Greater(t, n.right.key, n.key)
+		assert.Equal(t, n, n.right.parent)
+	}
+	assert.Equal(t, n.left.size+n.right.size+1, n.size)
+
+	lh, rh := checkTree(t, m, n.left), checkTree(t, m, n.right)
+	assert.Equal(t, lh, rh, "black height")
+	if n.red {
+		return lh
+	}
+	return lh + 1
+}
+
+func TestTreeMapRandom(t *testing.T) {
+	m := NewTreeMap[int, int]()
+	ref := make(map[int]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		k := r.Intn(500)
+		if r.Intn(3) == 0 {
+			_, ok := ref[k]
+			assert.Equal(t, ok, m.Delete(k))
+			delete(ref, k)
+		} else {
+			m.Put(k, i)
+			ref[k] = i
+		}
+	}
+	assert.False(t, m.root.red)
+	checkTree(t, m, m.root)
+
+	keys := make([]int, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	assert.Equal(t, keys, m.Keys())
+	assert.Equal(t, len(ref), m.Len())
+
+	for i, k := range keys {
+		assert.Equal(t, i, m.Rank(k))
+		got, v, ok := m.Select(i)
+		assert.True(t, ok)
+		assert.Equal(t, k, got)
+		assert.Equal(t, ref[k], v)
+	}
+
+	var desc []int
+	m.RangeDesc(func(k, _ int) bool {
+		desc = append(desc, k)
+		return true
+	})
+	for i, j := 0, len(desc)-1; i < j; i, j = i+1, j-1 {
+		desc[i], desc[j] = desc[j], desc[i]
+	}
+	assert.Equal(t, keys, desc)
+
+	for _, k := range keys {
+		m.Delete(k)
+	}
+	assert.Equal(t, 0, m.Len())
+	_, _, ok := m.Min()
+	assert.False(t, ok)
+}
+
+func TestTreeMapQuery(t *testing.T) {
+	var m TreeMap[int, string]
+	_, _, ok := m.Floor(1)
+	assert.False(t, ok)
+
+	for _, k := range []int{10, 20, 30, 40} {
+		m.Put(k, "")
+	}
+
+	k, _, ok := m.Floor(25)
+	assert.True(t, ok)
+	assert.Equal(t, 20, k)
+	k, _, _ = m.Floor(20)
+	assert.Equal(t, 20, k)
+	_, _, ok = m.Floor(5)
+	assert.False(t, ok)
+
+	k, _, _ = m.Ceiling(25)
+	assert.Equal(t, 30, k)
+	_, _, ok = m.Ceiling(41)
+	assert.False(t, ok)
+
+	k, _, _ = m.Lower(20)
+	assert.Equal(t, 10, k)
+	k, _, _ = m.Higher(20)
+	assert.Equal(t, 30, k)
+
+	assert.Equal(t, 2, m.Rank(25))
+	assert.Equal(t, 4, m.Rank(100))
+	_, _, ok = m.Select(4)
+	assert.False(t, ok)
+
+	k, _, _ = m.Min()
+	assert.Equal(t, 10, k)
+	k, _, _ = m.Max()
+	assert.Equal(t, 40, k)
+
+	var between []int
+	m.RangeBetween(15, 40, func(k int, _ string) bool {
+		between = append(between, k)
+		return true
+	})
+	assert.Equal(t, []int{20, 30}, between)
+}
+
+func TestTreeMapJSON(t *testing.T) {
+	m := NewTreeMap[float64, string]()
+	m.Put(2.5, "b")
+	m.Put(-1, "a")
+	m.Put(10, "c")
+
+	data, err := json.Marshal(m)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"-1":"a","2.5":"b","10":"c"}`, string(data))
+
+	var decoded struct {
+		M TreeMap[float64, string] `json:"m"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"m":`+string(data)+`}`), &decoded))
+	assert.Equal(t, []float64{-1, 2.5, 10}, decoded.M.Keys())
+	assert.Equal(t, []string{"a", "b", "c"}, decoded.M.Values())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"x":"a"}`), m))
+}
+
+func TestLinkedMap(t *testing.T) {
+	var m LinkedMap[string, int]
+	m.Set("c", 1)
+	m.Set("a", 2)
+	m.Set("b", 3)
+	m.Set("c", 4)
+	assert.Equal(t, []string{"c", "a", "b"}, m.Keys())
+	assert.Equal(t, []int{4, 2, 3}, m.Values())
+
+	assert.True(t, m.Delete("a"))
+	assert.False(t, m.Delete("a"))
+	m.Set("a", 5)
+	k, v, ok := m.First()
+	assert.True(t, ok)
+	assert.Equal(t, "c", k)
+	assert.Equal(t, 4, v)
+	k, _, _ = m.Last()
+	assert.Equal(t, "a", k)
+
+	m.Range(func(key string, _ int) bool {
+		m.Delete(key)
+		return true
+	})
+	assert.Equal(t, 0, m.Len())
+	_, _, ok = m.First()
+	assert.False(t, ok)
+
+	data := `{"z":1,"y":{"n":2},"x":null}`
+	l := NewLinkedMap[string, json.RawMessage]()
+	assert.Nil(t, json.Unmarshal([]byte(data), l))
+	assert.Equal(t, []string{"z", "y", "x"}, l.Keys())
+	out, err := json.Marshal(l)
+	assert.Nil(t, err)
+	assert.Equal(t, data, string(out))
+
+	ints := NewLinkedMap[int, bool]()
+	ints.Set(3, true)
+	ints.Set(1, false)
+	out, err = json.Marshal(ints)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"3":true,"1":false}`, string(out))
+	assert.Nil(t, json.Unmarshal([]byte("null"), ints))
+	assert.Equal(t, 0, ints.Len())
+}
+
+func TestSortedSet(t *testing.T) {
+	s := NewSortedSet("b", "d", "a", "c", "a")
+	assert.Equal(t, []string{"a", "b", "c", "d"}, s.Items())
+	s.Remove("c")
+	assert.False(t, s.Contains("c"))
+
+	v, ok := s.Floor("c")
+	assert.True(t, ok)
+	assert.Equal(t, "b", v)
+	v, _ = s.Ceiling("c")
+	assert.Equal(t, "d", v)
+	assert.Equal(t, 2, s.Rank("c"))
+	v, _ = s.Select(0)
+	assert.Equal(t, "a", v)
+	v, _ = s.Max()
+	assert.Equal(t, "d", v)
+
+	data, err := json.Marshal(s)
+	assert.Nil(t, err)
+	assert.Equal(t, `["a","b","d"]`, string(data))
+
+	var decoded SortedSet[int]
+	assert.Nil(t, json.Unmarshal([]byte(`[3,1,2,1]`), &decoded))
+	assert.Equal(t, []int{1, 2, 3}, decoded.Items())
+	assert.True(t, decoded.ToSet().Equals(NewSet(1, 2, 3)))
+}
@@ -0,0 +1,164 @@
+package hlog
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrAsyncWriterClosed is returned by the writes after AsyncWriter.Close.
+var ErrAsyncWriterClosed = errors.New("async writer is closed")
+
+// AsyncOptions controls the behavior of AsyncWriter.
+type AsyncOptions struct {
+	// BufferSize is the max number of pending writes, 1024 by default.
+	BufferSize int
+
+	// Block makes writes wait when the buffer is full,
+	// otherwise the logs are dropped.
+	Block bool
+
+	// FlushInterval is the interval to flush the buffered logs to the
+	// underlying writer, 1 second by default.
+	FlushInterval time.Duration
+}
+
+// AsyncWriter is an io.Writer which writes to the underlying writer in a
+// background goroutine, so that logging does not wait for the disk.
+// It can be passed to SetOutput, and Sync should be called on shutdown.
+type AsyncWriter struct {
+	w  io.Writer
+	bw *bufio.Writer
+
+	ch      chan []byte
+	syncCh  chan chan error
+	done    chan struct{}
+	stopped chan struct{}
+
+	// mu guards closed, so that no write is enqueued after Close drains
+	// the pending logs.
+	mu      sync.RWMutex
+	closed  bool
+	block   bool
+	dropped atomic.Uint64
+}
+
+// NewAsyncWriter returns an AsyncWriter writing to w.
+func NewAsyncWriter(w io.Writer, opt AsyncOptions) *AsyncWriter {
+	if opt.BufferSize <= 0 {
+		opt.BufferSize = 1024
+	}
+	if opt.FlushInterval <= 0 {
+		opt.FlushInterval = time.Second
+	}
+
+	a := &AsyncWriter{
+		w:       w,
+		bw:      bufio.NewWriter(w),
+		ch:      make(chan []byte, opt.BufferSize),
+		syncCh:  make(chan chan error),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		block:   opt.Block,
+	}
+	go a.run(opt.FlushInterval)
+	return a
+}
+
+// Write implements io.Writer. p is copied, since the callers
+// like log.Logger reuse it.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return 0, ErrAsyncWriterClosed
+	}
+
+	b := make([]byte, len(p))
+	copy(b, p)
+
+	if a.block {
+		// the background goroutine keeps consuming until Close gets the lock
+		a.ch <- b
+		return len(p), nil
+	}
+
+	select {
+	case a.ch <- b:
+	default:
+		a.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of logs dropped because the buffer was full.
+func (a *AsyncWriter) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Sync waits until the pending logs are written and flushed. If the
+// underlying writer has a Sync method, e.g. *os.File, it is called too.
+func (a *AsyncWriter) Sync() error {
+	reply := make(chan error, 1)
+	select {
+	case a.syncCh <- reply:
+		return <-reply
+	case <-a.stopped:
+		return ErrAsyncWriterClosed
+	}
+}
+
+// Close syncs the pending logs and stops the background goroutine.
+// The underlying writer is not closed.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return ErrAsyncWriterClosed
+	}
+	a.closed = true
+	a.mu.Unlock()
+
+	close(a.done)
+	<-a.stopped
+	return a.sync()
+}
+
+func (a *AsyncWriter) run(flushInterval time.Duration) {
+	defer close(a.stopped)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case b := <-a.ch:
+			a.bw.Write(b)
+		case <-ticker.C:
+			a.bw.Flush()
+		case reply := <-a.syncCh:
+			reply <- a.sync()
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// sync writes all the pending logs, it must be called by the background
+// goroutine or after it is stopped.
+func (a *AsyncWriter) sync() error {
+	for n := len(a.ch); n > 0; n-- {
+		a.bw.Write(<-a.ch)
+	}
+
+	if err := a.bw.Flush(); err != nil {
+		return err
+	}
+	if s, ok := a.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package hlog
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	entered sync.Once
+	enter   chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.entered.Do(func() { close(w.enter) })
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriter_Sync(t *testing.T) {
+	w := &blockingWriter{enter: make(chan struct{}), release: make(chan struct{})}
+	close(w.release)
+
+	a := NewAsyncWriter(w, AsyncOptions{FlushInterval: time.Hour})
+	l := &defaultLogger{stdlog: log.New(a, "", 0), level: newLevelVar(LevelTrace)}
+	for i := 0; i < 100; i++ {
+		l.Infof("line %d", i)
+	}
+
+	assert.Nil(t, a.Sync())
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	assert.Equal(t, 100, len(lines))
+	assert.Equal(t, "[Info] line 99", lines[99])
+
+	l.Info("last")
+	assert.Nil(t, a.Close())
+	assert.True(t, strings.HasSuffix(w.String(), "[Info] last\n"))
+
+	_, err := a.Write([]byte("closed"))
+	assert.Equal(t, ErrAsyncWriterClosed, err)
+	assert.Equal(t, ErrAsyncWriterClosed, a.Sync())
+}
+
+func TestAsyncWriter_Drop(t *testing.T) {
+	w := &blockingWriter{enter: make(chan struct{}), release: make(chan struct{})}
+	a := NewAsyncWriter(w, AsyncOptions{BufferSize: 2, FlushInterval: time.Millisecond})
+
+	// block the background goroutine in the periodic flush
+	a.Write([]byte("x"))
+	<-w.enter
+
+	for i := 0; i < 10; i++ {
+		n, err := a.Write([]byte("x"))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, n)
+	}
+	assert.Equal(t, uint64(8), a.Dropped())
+
+	close(w.release)
+	assert.Nil(t, a.Close())
+	assert.Equal(t, "xxx", w.String())
+}
+
+func TestCountSampler(t *testing.T) {
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	mockCurrentTime(t, &now)
+
+	var buf bytes.Buffer
+	l := newTestLogger(&buf)
+	l.SetSampler(NewCountSampler(2, 3, time.Second))
+
+	for i := 0; i < 10; i++ {
+		l.Errorf("retry %d", i)
+	}
+	l.Error("other")
+	assert.Equal(t, "[Error] retry 0\n[Error] retry 1\n[Error] retry 4\n[Error] retry 7\n[Error] other\n", buf.String())
+
+	buf.Reset()
+	now = now.Add(time.Second)
+	l.Errorf("retry %d", 10)
+	assert.Equal(t, "[Error] retry 10\n", buf.String())
+}
+
+func TestRateSampler(t *testing.T) {
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	mockCurrentTime(t, &now)
+
+	s := NewRateSampler(2, time.Second)
+	assert.True(t, s.Sample(LevelInfo, "a"))
+	assert.True(t, s.Sample(LevelInfo, "a"))
+	assert.False(t, s.Sample(LevelInfo, "a"))
+	assert.True(t, s.Sample(LevelWarn, "a"))
+	now = now.Add(time.Second)
+	assert.True(t, s.Sample(LevelInfo, "a"))
+
+	// counted in total without a tick
+	s = NewRateSampler(2, 0)
+	for i := 0; i < 2; i++ {
+		assert.True(t, s.Sample(LevelInfo, "a"))
+	}
+	now = now.Add(time.Hour)
+	assert.False(t, s.Sample(LevelInfo, "a"))
+}
+
+type samplerFunc func(lv Level, key string) bool
+
+func (f samplerFunc) Sample(lv Level, key string) bool { return f(lv, key) }
+
+func TestSampler_Fatal(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestLogger(&buf)
+	l.SetSampler(samplerFunc(func(Level, string) bool { return false }))
+
+	l.Error("dropped")
+	assert.Empty(t, buf.String())
+	assert.True(t, l.sample(LevelFatal, "exit"))
+}
+
+func TestSampleCounter_Concurrent(t *testing.T) {
+	const first = 10
+	var c sampleCounter
+	c.n.Store(1000)
+	c.resetAt.Store(100)
+
+	var (
+		wg      sync.WaitGroup
+		sampled atomic.Int64
+		resets  atomic.Int64
+	)
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				n := c.inc(200, 1000)
+				if n <= first {
+					sampled.Add(1)
+				}
+				if n == 1 {
+					resets.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), resets.Load())
+	assert.Equal(t, int64(first), sampled.Load())
+	assert.Equal(t, int64(1200), c.resetAt.Load())
+}
+
+func TestAsyncWriter_CloseConcurrently(t *testing.T) {
+	for _, block := range []bool{false, true} {
+		w := &blockingWriter{enter: make(chan struct{}), release: make(chan struct{})}
+		close(w.release)
+		a := NewAsyncWriter(w, AsyncOptions{BufferSize: 8, Block: block})
+
+		var (
+			wg      sync.WaitGroup
+			written atomic.Uint64
+		)
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				// write until closed, racing with Close
+				for {
+					if _, err := a.Write([]byte("x")); err != nil {
+						return
+					}
+					written.Add(1)
+				}
+			}()
+		}
+		time.Sleep(time.Millisecond)
+		assert.Nil(t, a.Close())
+		wg.Wait()
+
+		assert.Equal(t, written.Load(), uint64(len(w.String()))+a.Dropped())
+	}
+}
@@ -0,0 +1,63 @@
+package hlog
+
+import "context"
+
+// ContextExtractor returns the fields carried by a context, which are
+// printed by the Ctx* methods of the default logger.
+type ContextExtractor func(ctx context.Context) []Field
+
+type ctxFieldsKey struct{}
+
+var extractors = []ContextExtractor{fieldsFromContext}
+
+// WithContextFields returns a copy of ctx carrying the key-value pairs,
+// e.g. the request ID, trace ID or user of a request. The pairs are added
+// to the ones already carried by ctx.
+func WithContextFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	fields := appendFields(fieldsFromContext(ctx), toFields(keysAndValues)...)
+	return context.WithValue(ctx, ctxFieldsKey{}, fields)
+}
+
+// RegisterContextExtractor adds extractors used by ContextFields, so that
+// values put in the context by middlewares show up in every log line.
+// Note that this method is not concurrent-safe and should be called
+// during initialization.
+func RegisterContextExtractor(e ...ContextExtractor) {
+	extractors = append(extractors, e...)
+}
+
+// ContextValueExtractor returns a ContextExtractor which outputs
+// ctx.Value(ctxKey) with the field key. Nothing is output if the value
+// is not set.
+func ContextValueExtractor(key string, ctxKey interface{}) ContextExtractor {
+	return func(ctx context.Context) []Field {
+		v := ctx.Value(ctxKey)
+		if v == nil {
+			return nil
+		}
+		return []Field{{Key: key, Value: v}}
+	}
+}
+
+// ContextFields returns the fields extracted from ctx by all the
+// registered extractors. Loggers set by SetLogger may use it to print
+// the same fields as the default logger.
+func ContextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields []Field
+	for _, e := range extractors {
+		fields = append(fields, e(ctx)...)
+	}
+	return fields
+}
+
+func fieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(ctxFieldsKey{}).([]Field)
+	return fields
+}
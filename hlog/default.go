@@ -6,24 +6,60 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/joker-circus/gotools/bytebufferpool"
 )
 
+const defaultFlags = log.LstdFlags | log.Lshortfile | log.Lmicroseconds
+
+// callerDepth is the number of frames from output to the caller of the
+// logger: output, logf/logw/ctxLogf, the logging method, the caller.
+const callerDepth = 3
+
 var logger FullLogger = &defaultLogger{
-	stdlog: log.New(os.Stderr, "", log.LstdFlags|log.Lshortfile|log.Lmicroseconds),
+	stdlog: log.New(os.Stderr, "", defaultFlags),
+	level:  rootLevel,
 }
 
+// global is the logger called by the package-level functions.
+var global = globalLogger(logger)
+
 // SetOutput sets the output of default logger. By default, it is stderr.
 func SetOutput(w io.Writer) {
 	logger.SetOutput(w)
 }
 
 // SetLevel sets the level of logs below which logs will not be output.
-// The default log level is LevelTrace.
-// Note that this method is not concurrent-safe.
+// The default log level is LevelTrace, which can be changed by the
+// environment variable HLOG_LEVEL.
 func SetLevel(lv Level) {
 	logger.SetLevel(lv)
 }
 
+// SetEncoder sets the encoder of default logger, e.g. JSONEncoder or
+// LogfmtEncoder. The encoder takes over the time and caller of the logs.
+// A nil encoder restores the default "[Level] message" output.
+// It takes no effect if the logger set by SetLogger does not support encoders.
+// Note that this method is not concurrent-safe.
+func SetEncoder(enc Encoder) {
+	if l, ok := logger.(interface{ SetEncoder(Encoder) }); ok {
+		l.SetEncoder(enc)
+	}
+}
+
+// SetSampler sets the sampler of default logger, nil disables sampling.
+// It takes no effect if the logger set by SetLogger does not support samplers.
+// Note that this method is not concurrent-safe.
+func SetSampler(s Sampler) {
+	if l, ok := logger.(interface{ SetSampler(Sampler) }); ok {
+		l.SetSampler(s)
+	}
+}
+
 // DefaultLogger return the default logger for hertz.
 func DefaultLogger() FullLogger {
 	return logger
@@ -34,222 +70,523 @@ func DefaultLogger() FullLogger {
 // after the use of DefaultLogger and global functions in this package.
 func SetLogger(v FullLogger) {
 	logger = v
+	global = globalLogger(v)
+}
+
+// globalLogger wraps the default implementation to skip the frame of the
+// package-level functions, other loggers are called by them directly.
+func globalLogger(l FullLogger) FullLogger {
+	if ll, ok := l.(*defaultLogger); ok {
+		return pkgLogger{ll}
+	}
+	return l
 }
 
 // Fatal calls the default logger's Fatal method and then os.Exit(1).
 func Fatal(v ...interface{}) {
-	logger.Fatal(v...)
+	global.Fatal(v...)
 }
 
 // Error calls the default logger's Error method.
 func Error(v ...interface{}) {
-	logger.Error(v...)
+	global.Error(v...)
 }
 
 // Warn calls the default logger's Warn method.
 func Warn(v ...interface{}) {
-	logger.Warn(v...)
+	global.Warn(v...)
 }
 
 // Notice calls the default logger's Notice method.
 func Notice(v ...interface{}) {
-	logger.Notice(v...)
+	global.Notice(v...)
 }
 
 // Info calls the default logger's Info method.
 func Info(v ...interface{}) {
-	logger.Info(v...)
+	global.Info(v...)
 }
 
 // Debug calls the default logger's Debug method.
 func Debug(v ...interface{}) {
-	logger.Debug(v...)
+	global.Debug(v...)
 }
 
 // Trace calls the default logger's Trace method.
 func Trace(v ...interface{}) {
-	logger.Trace(v...)
+	global.Trace(v...)
 }
 
 // Fatalf calls the default logger's Fatalf method and then os.Exit(1).
 func Fatalf(format string, v ...interface{}) {
-	logger.Fatalf(format, v...)
+	global.Fatalf(format, v...)
 }
 
 // Errorf calls the default logger's Errorf method.
 func Errorf(format string, v ...interface{}) {
-	logger.Errorf(format, v...)
+	global.Errorf(format, v...)
 }
 
 // Warnf calls the default logger's Warnf method.
 func Warnf(format string, v ...interface{}) {
-	logger.Warnf(format, v...)
+	global.Warnf(format, v...)
 }
 
 // Noticef calls the default logger's Noticef method.
 func Noticef(format string, v ...interface{}) {
-	logger.Noticef(format, v...)
+	global.Noticef(format, v...)
 }
 
 // Infof calls the default logger's Infof method.
 func Infof(format string, v ...interface{}) {
-	logger.Infof(format, v...)
+	global.Infof(format, v...)
 }
 
 // Debugf calls the default logger's Debugf method.
 func Debugf(format string, v ...interface{}) {
-	logger.Debugf(format, v...)
+	global.Debugf(format, v...)
 }
 
 // Tracef calls the default logger's Tracef method.
 func Tracef(format string, v ...interface{}) {
-	logger.Tracef(format, v...)
+	global.Tracef(format, v...)
+}
+
+// With returns a child of the default logger which adds the key-value pairs
+// to every log. If the logger set by SetLogger is not a FieldLogger, the
+// pairs are appended to the message in logfmt.
+func With(keysAndValues ...interface{}) FieldLogger {
+	return fieldLoggerOf(logger).With(keysAndValues...)
+}
+
+// Fatalw calls the default logger's Fatalw method and then os.Exit(1).
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	fieldLoggerOf(global).Fatalw(msg, keysAndValues...)
+}
+
+// Errorw calls the default logger's Errorw method.
+func Errorw(msg string, keysAndValues ...interface{}) {
+	fieldLoggerOf(global).Errorw(msg, keysAndValues...)
+}
+
+// Warnw calls the default logger's Warnw method.
+func Warnw(msg string, keysAndValues ...interface{}) {
+	fieldLoggerOf(global).Warnw(msg, keysAndValues...)
+}
+
+// Noticew calls the default logger's Noticew method.
+func Noticew(msg string, keysAndValues ...interface{}) {
+	fieldLoggerOf(global).Noticew(msg, keysAndValues...)
+}
+
+// Infow calls the default logger's Infow method.
+func Infow(msg string, keysAndValues ...interface{}) {
+	fieldLoggerOf(global).Infow(msg, keysAndValues...)
+}
+
+// Debugw calls the default logger's Debugw method.
+func Debugw(msg string, keysAndValues ...interface{}) {
+	fieldLoggerOf(global).Debugw(msg, keysAndValues...)
+}
+
+// Tracew calls the default logger's Tracew method.
+func Tracew(msg string, keysAndValues ...interface{}) {
+	fieldLoggerOf(global).Tracew(msg, keysAndValues...)
 }
 
 // CtxFatalf calls the default logger's CtxFatalf method and then os.Exit(1).
 func CtxFatalf(ctx context.Context, format string, v ...interface{}) {
-	logger.CtxFatalf(ctx, format, v...)
+	global.CtxFatalf(ctx, format, v...)
 }
 
 // CtxErrorf calls the default logger's CtxErrorf method.
 func CtxErrorf(ctx context.Context, format string, v ...interface{}) {
-	logger.CtxErrorf(ctx, format, v...)
+	global.CtxErrorf(ctx, format, v...)
 }
 
 // CtxWarnf calls the default logger's CtxWarnf method.
 func CtxWarnf(ctx context.Context, format string, v ...interface{}) {
-	logger.CtxWarnf(ctx, format, v...)
+	global.CtxWarnf(ctx, format, v...)
 }
 
 // CtxNoticef calls the default logger's CtxNoticef method.
 func CtxNoticef(ctx context.Context, format string, v ...interface{}) {
-	logger.CtxNoticef(ctx, format, v...)
+	global.CtxNoticef(ctx, format, v...)
 }
 
 // CtxInfof calls the default logger's CtxInfof method.
 func CtxInfof(ctx context.Context, format string, v ...interface{}) {
-	logger.CtxInfof(ctx, format, v...)
+	global.CtxInfof(ctx, format, v...)
 }
 
 // CtxDebugf calls the default logger's CtxDebugf method.
 func CtxDebugf(ctx context.Context, format string, v ...interface{}) {
-	logger.CtxDebugf(ctx, format, v...)
+	global.CtxDebugf(ctx, format, v...)
 }
 
 // CtxTracef calls the default logger's CtxTracef method.
 func CtxTracef(ctx context.Context, format string, v ...interface{}) {
-	logger.CtxTracef(ctx, format, v...)
+	global.CtxTracef(ctx, format, v...)
 }
 
 type defaultLogger struct {
-	stdlog *log.Logger
-	level  Level
+	stdlog  *log.Logger
+	level   *levelVar
+	encoder Encoder
+	sampler Sampler
+	fields  []Field
 }
 
 func (ll *defaultLogger) SetOutput(w io.Writer) {
 	ll.stdlog.SetOutput(w)
 }
 
+// SetLevel sets the level atomically. Loggers returned by With share
+// the level with their parent.
 func (ll *defaultLogger) SetLevel(lv Level) {
-	ll.level = lv
+	ll.level.Set(lv)
+}
+
+// SetEncoder sets the encoder of the logger.
+// Loggers returned by With share the output with their parent, so the
+// encoder should be set before With is called.
+func (ll *defaultLogger) SetEncoder(enc Encoder) {
+	ll.encoder = enc
+	if enc == nil {
+		ll.stdlog.SetFlags(defaultFlags)
+	} else {
+		ll.stdlog.SetFlags(0)
+	}
 }
 
-func (ll *defaultLogger) logf(lv Level, format *string, v ...interface{}) {
-	if ll.level > lv {
+// SetSampler sets the sampler of the logger, nil disables sampling.
+func (ll *defaultLogger) SetSampler(s Sampler) {
+	ll.sampler = s
+}
+
+func (ll *defaultLogger) With(keysAndValues ...interface{}) FieldLogger {
+	return &defaultLogger{
+		stdlog:  ll.stdlog,
+		level:   ll.level,
+		encoder: ll.encoder,
+		sampler: ll.sampler,
+		fields:  appendFields(ll.fields, toFields(keysAndValues)...),
+	}
+}
+
+func (ll *defaultLogger) named(name string) FieldLogger {
+	return &defaultLogger{
+		stdlog:  ll.stdlog,
+		level:   moduleLevel(name),
+		encoder: ll.encoder,
+		sampler: ll.sampler,
+		fields:  appendFields([]Field{{Key: LoggerKey, Value: name}}, ll.fields...),
+	}
+}
+
+func (ll *defaultLogger) logf(skip int, lv Level, format *string, v ...interface{}) {
+	if ll.level.Level() > lv {
 		return
 	}
-	msg := lv.toString()
+	var msg string
 	if format != nil {
-		msg += fmt.Sprintf(*format, v...)
+		if !ll.sample(lv, *format) {
+			return
+		}
+		msg = fmt.Sprintf(*format, v...)
 	} else {
-		msg += fmt.Sprint(v...)
+		msg = fmt.Sprint(v...)
+		if !ll.sample(lv, msg) {
+			return
+		}
+	}
+	ll.output(skip, lv, msg, ll.fields)
+}
+
+func (ll *defaultLogger) ctxLogf(skip int, ctx context.Context, lv Level, format string, v ...interface{}) {
+	if ll.level.Level() > lv || !ll.sample(lv, format) {
+		return
+	}
+	ll.output(skip, lv, fmt.Sprintf(format, v...), appendFields(ll.fields, ContextFields(ctx)...))
+}
+
+func (ll *defaultLogger) logw(skip int, lv Level, msg string, keysAndValues []interface{}) {
+	if ll.level.Level() > lv || !ll.sample(lv, msg) {
+		return
 	}
-	ll.stdlog.Output(4, msg)
+	ll.output(skip, lv, msg, appendFields(ll.fields, toFields(keysAndValues)...))
+}
+
+// sample reports whether the log is output, Fatal logs are never sampled
+// so that the process always exits.
+func (ll *defaultLogger) sample(lv Level, key string) bool {
+	return lv >= LevelFatal || ll.sampler == nil || ll.sampler.Sample(lv, key)
+}
+
+// output must be called by logf, logw or ctxLogf, so that the caller is
+// always found at callerDepth, plus skip frames between the caller and the
+// logging method.
+func (ll *defaultLogger) output(skip int, lv Level, msg string, fields []Field) {
+	buf := bytebufferpool.Get()
+	if !ll.encode(buf, skip, lv, msg, fields) {
+		buf.WriteString(lv.toString())
+		buf.WriteString(msg)
+		writeLogfmtFields(buf, fields)
+	}
+	ll.stdlog.Output(callerDepth+skip+1, buf.String())
+	bytebufferpool.Put(buf)
 	if lv == LevelFatal {
 		os.Exit(1)
 	}
 }
 
+func (ll *defaultLogger) encode(buf *bytebufferpool.ByteBuffer, skip int, lv Level, msg string, fields []Field) bool {
+	if ll.encoder == nil {
+		return false
+	}
+
+	e := &Entry{
+		Time:    time.Now(),
+		Level:   lv,
+		Message: msg,
+		Fields:  fields,
+	}
+	if _, file, line, ok := runtime.Caller(callerDepth + skip + 1); ok {
+		e.Caller = filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+	if err := ll.encoder.Encode(buf, e); err != nil {
+		buf.Reset()
+		return false
+	}
+	return true
+}
+
 func (ll *defaultLogger) Fatal(v ...interface{}) {
-	ll.logf(LevelFatal, nil, v...)
+	ll.logf(0, LevelFatal, nil, v...)
 }
 
 func (ll *defaultLogger) Error(v ...interface{}) {
-	ll.logf(LevelError, nil, v...)
+	ll.logf(0, LevelError, nil, v...)
 }
 
 func (ll *defaultLogger) Warn(v ...interface{}) {
-	ll.logf(LevelWarn, nil, v...)
+	ll.logf(0, LevelWarn, nil, v...)
 }
 
 func (ll *defaultLogger) Notice(v ...interface{}) {
-	ll.logf(LevelNotice, nil, v...)
+	ll.logf(0, LevelNotice, nil, v...)
 }
 
 func (ll *defaultLogger) Info(v ...interface{}) {
-	ll.logf(LevelInfo, nil, v...)
+	ll.logf(0, LevelInfo, nil, v...)
 }
 
 func (ll *defaultLogger) Debug(v ...interface{}) {
-	ll.logf(LevelDebug, nil, v...)
+	ll.logf(0, LevelDebug, nil, v...)
 }
 
 func (ll *defaultLogger) Trace(v ...interface{}) {
-	ll.logf(LevelTrace, nil, v...)
+	ll.logf(0, LevelTrace, nil, v...)
 }
 
 func (ll *defaultLogger) Fatalf(format string, v ...interface{}) {
-	ll.logf(LevelFatal, &format, v...)
+	ll.logf(0, LevelFatal, &format, v...)
 }
 
 func (ll *defaultLogger) Errorf(format string, v ...interface{}) {
-	ll.logf(LevelError, &format, v...)
+	ll.logf(0, LevelError, &format, v...)
 }
 
 func (ll *defaultLogger) Warnf(format string, v ...interface{}) {
-	ll.logf(LevelWarn, &format, v...)
+	ll.logf(0, LevelWarn, &format, v...)
 }
 
 func (ll *defaultLogger) Noticef(format string, v ...interface{}) {
-	ll.logf(LevelNotice, &format, v...)
+	ll.logf(0, LevelNotice, &format, v...)
 }
 
 func (ll *defaultLogger) Infof(format string, v ...interface{}) {
-	ll.logf(LevelInfo, &format, v...)
+	ll.logf(0, LevelInfo, &format, v...)
 }
 
 func (ll *defaultLogger) Debugf(format string, v ...interface{}) {
-	ll.logf(LevelDebug, &format, v...)
+	ll.logf(0, LevelDebug, &format, v...)
 }
 
 func (ll *defaultLogger) Tracef(format string, v ...interface{}) {
-	ll.logf(LevelTrace, &format, v...)
+	ll.logf(0, LevelTrace, &format, v...)
 }
 
 func (ll *defaultLogger) CtxFatalf(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelFatal, &format, v...)
+	ll.ctxLogf(0, ctx, LevelFatal, format, v...)
 }
 
 func (ll *defaultLogger) CtxErrorf(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelError, &format, v...)
+	ll.ctxLogf(0, ctx, LevelError, format, v...)
 }
 
 func (ll *defaultLogger) CtxWarnf(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelWarn, &format, v...)
+	ll.ctxLogf(0, ctx, LevelWarn, format, v...)
 }
 
 func (ll *defaultLogger) CtxNoticef(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelNotice, &format, v...)
+	ll.ctxLogf(0, ctx, LevelNotice, format, v...)
 }
 
 func (ll *defaultLogger) CtxInfof(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelInfo, &format, v...)
+	ll.ctxLogf(0, ctx, LevelInfo, format, v...)
 }
 
 func (ll *defaultLogger) CtxDebugf(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelDebug, &format, v...)
+	ll.ctxLogf(0, ctx, LevelDebug, format, v...)
 }
 
 func (ll *defaultLogger) CtxTracef(ctx context.Context, format string, v ...interface{}) {
-	ll.logf(LevelTrace, &format, v...)
+	ll.ctxLogf(0, ctx, LevelTrace, format, v...)
+}
+
+func (ll *defaultLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	ll.logw(0, LevelFatal, msg, keysAndValues)
+}
+
+func (ll *defaultLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	ll.logw(0, LevelError, msg, keysAndValues)
+}
+
+func (ll *defaultLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	ll.logw(0, LevelWarn, msg, keysAndValues)
+}
+
+func (ll *defaultLogger) Noticew(msg string, keysAndValues ...interface{}) {
+	ll.logw(0, LevelNotice, msg, keysAndValues)
+}
+
+func (ll *defaultLogger) Infow(msg string, keysAndValues ...interface{}) {
+	ll.logw(0, LevelInfo, msg, keysAndValues)
+}
+
+func (ll *defaultLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	ll.logw(0, LevelDebug, msg, keysAndValues)
+}
+
+func (ll *defaultLogger) Tracew(msg string, keysAndValues ...interface{}) {
+	ll.logw(0, LevelTrace, msg, keysAndValues)
+}
+
+// pkgLogger is the default logger called by the package-level functions,
+// which skips one more frame of them to find the caller.
+type pkgLogger struct {
+	*defaultLogger
+}
+
+func (l pkgLogger) Fatal(v ...interface{}) {
+	l.logf(1, LevelFatal, nil, v...)
+}
+
+func (l pkgLogger) Error(v ...interface{}) {
+	l.logf(1, LevelError, nil, v...)
+}
+
+func (l pkgLogger) Warn(v ...interface{}) {
+	l.logf(1, LevelWarn, nil, v...)
+}
+
+func (l pkgLogger) Notice(v ...interface{}) {
+	l.logf(1, LevelNotice, nil, v...)
+}
+
+func (l pkgLogger) Info(v ...interface{}) {
+	l.logf(1, LevelInfo, nil, v...)
+}
+
+func (l pkgLogger) Debug(v ...interface{}) {
+	l.logf(1, LevelDebug, nil, v...)
+}
+
+func (l pkgLogger) Trace(v ...interface{}) {
+	l.logf(1, LevelTrace, nil, v...)
+}
+
+func (l pkgLogger) Fatalf(format string, v ...interface{}) {
+	l.logf(1, LevelFatal, &format, v...)
+}
+
+func (l pkgLogger) Errorf(format string, v ...interface{}) {
+	l.logf(1, LevelError, &format, v...)
+}
+
+func (l pkgLogger) Warnf(format string, v ...interface{}) {
+	l.logf(1, LevelWarn, &format, v...)
+}
+
+func (l pkgLogger) Noticef(format string, v ...interface{}) {
+	l.logf(1, LevelNotice, &format, v...)
+}
+
+func (l pkgLogger) Infof(format string, v ...interface{}) {
+	l.logf(1, LevelInfo, &format, v...)
+}
+
+func (l pkgLogger) Debugf(format string, v ...interface{}) {
+	l.logf(1, LevelDebug, &format, v...)
+}
+
+func (l pkgLogger) Tracef(format string, v ...interface{}) {
+	l.logf(1, LevelTrace, &format, v...)
+}
+
+func (l pkgLogger) CtxFatalf(ctx context.Context, format string, v ...interface{}) {
+	l.ctxLogf(1, ctx, LevelFatal, format, v...)
+}
+
+func (l pkgLogger) CtxErrorf(ctx context.Context, format string, v ...interface{}) {
+	l.ctxLogf(1, ctx, LevelError, format, v...)
+}
+
+func (l pkgLogger) CtxWarnf(ctx context.Context, format string, v ...interface{}) {
+	l.ctxLogf(1, ctx, LevelWarn, format, v...)
+}
+
+func (l pkgLogger) CtxNoticef(ctx context.Context, format string, v ...interface{}) {
+	l.ctxLogf(1, ctx, LevelNotice, format, v...)
+}
+
+func (l pkgLogger) CtxInfof(ctx context.Context, format string, v ...interface{}) {
+	l.ctxLogf(1, ctx, LevelInfo, format, v...)
+}
+
+func (l pkgLogger) CtxDebugf(ctx context.Context, format string, v ...interface{}) {
+	l.ctxLogf(1, ctx, LevelDebug, format, v...)
+}
+
+func (l pkgLogger) CtxTracef(ctx context.Context, format string, v ...interface{}) {
+	l.ctxLogf(1, ctx, LevelTrace, format, v...)
+}
+
+func (l pkgLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.logw(1, LevelFatal, msg, keysAndValues)
+}
+
+func (l pkgLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.logw(1, LevelError, msg, keysAndValues)
+}
+
+func (l pkgLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.logw(1, LevelWarn, msg, keysAndValues)
+}
+
+func (l pkgLogger) Noticew(msg string, keysAndValues ...interface{}) {
+	l.logw(1, LevelNotice, msg, keysAndValues)
+}
+
+func (l pkgLogger) Infow(msg string, keysAndValues ...interface{}) {
+	l.logw(1, LevelInfo, msg, keysAndValues)
+}
+
+func (l pkgLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.logw(1, LevelDebug, msg, keysAndValues)
+}
+
+func (l pkgLogger) Tracew(msg string, keysAndValues ...interface{}) {
+	l.logw(1, LevelTrace, msg, keysAndValues)
 }
@@ -0,0 +1,246 @@
+package hlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger(buf *bytes.Buffer) *defaultLogger {
+	return &defaultLogger{stdlog: log.New(buf, "", 0), level: newLevelVar(LevelTrace)}
+}
+
+func TestDefaultLogger_Infow(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestLogger(&buf)
+
+	l.Infow("hello", "user", "joker", Int("age", 18), "dangling")
+	assert.Equal(t, "[Info] hello user=joker age=18 !BADKEY=dangling\n", buf.String())
+
+	buf.Reset()
+	l.Infof("hello %s", "world")
+	assert.Equal(t, "[Info] hello world\n", buf.String())
+
+	buf.Reset()
+	l.SetLevel(LevelWarn)
+	l.Infow("hello")
+	assert.Equal(t, "", buf.String())
+}
+
+func TestDefaultLogger_With(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestLogger(&buf)
+
+	child := l.With("request_id", "abc")
+	child.With("step", 1).Errorw("failed", Err(errors.New("boom")))
+	assert.Equal(t, "[Error] failed request_id=abc step=1 error=boom\n", buf.String())
+
+	buf.Reset()
+	child.Warn("plain")
+	assert.Equal(t, "[Warn] plain request_id=abc\n", buf.String())
+
+	buf.Reset()
+	l.Info("parent")
+	assert.Equal(t, "[Info] parent\n", buf.String())
+}
+
+func TestDefaultLogger_JSONEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	old := logger
+	defer SetLogger(old)
+
+	SetLogger(newTestLogger(&buf))
+	SetEncoder(JSONEncoder{})
+	Infow("hello \"world\"", "user", "joker", "n", 1.5)
+
+	var m map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &m))
+	assert.Equal(t, "info", m[LevelKey])
+	assert.Equal(t, "hello \"world\"", m[MessageKey])
+	assert.Equal(t, "joker", m["user"])
+	assert.Equal(t, 1.5, m["n"])
+	assert.True(t, strings.HasPrefix(m[CallerKey].(string), "default_test.go:"))
+}
+
+func TestDefaultLogger_LogfmtEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	old := logger
+	defer SetLogger(old)
+
+	SetLogger(newTestLogger(&buf))
+	SetEncoder(LogfmtEncoder{TimeLayout: "2006"})
+	Warnw("disk full", "path", "/data 1", "empty", "")
+	line := buf.String()
+	assert.True(t, strings.Contains(line, ` level=warn caller=default_test.go:`), line)
+	assert.True(t, strings.HasSuffix(line, ` msg="disk full" path="/data 1" empty=""`+"\n"), line)
+}
+
+type printfLogger struct {
+	FullLogger
+	msgs []string
+}
+
+func (l *printfLogger) Info(v ...interface{}) {
+	l.msgs = append(l.msgs, v[0].(string))
+}
+
+func TestWith_FullLogger(t *testing.T) {
+	old := logger
+	defer SetLogger(old)
+
+	l := &printfLogger{}
+	SetLogger(l)
+	With("a", 1).Infow("hello", "b", true)
+	Infow("bye")
+	assert.Equal(t, []string{"hello a=1 b=true", "bye"}, l.msgs)
+}
+
+type userKey struct{}
+
+func TestDefaultLogger_CtxFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestLogger(&buf)
+
+	old := extractors
+	defer func() { extractors = old }()
+	RegisterContextExtractor(ContextValueExtractor("user", userKey{}))
+
+	ctx := WithContextFields(context.Background(), "request_id", "abc")
+	ctx = WithContextFields(ctx, String("trace_id", "t1"))
+	ctx = context.WithValue(ctx, userKey{}, "joker")
+	l.With("module", "test").CtxInfof(ctx, "hello %d", 1)
+	assert.Equal(t, "[Info] hello 1 module=test request_id=abc trace_id=t1 user=joker\n", buf.String())
+
+	buf.Reset()
+	l.CtxInfof(context.Background(), "hello")
+	assert.Equal(t, "[Info] hello\n", buf.String())
+}
+
+func TestNamed(t *testing.T) {
+	var buf bytes.Buffer
+	old := logger
+	defer SetLogger(old)
+	defer rootLevel.Set(rootLevel.Level())
+
+	SetLogger(&defaultLogger{stdlog: log.New(&buf, "", 0), level: rootLevel})
+	assert.Nil(t, SetLevels("test_pool=debug, *=warn"))
+
+	pool := Named("test_pool")
+	pool.Debugw("started", "size", 2)
+	Named("test_other").Info("dropped")
+	Info("dropped")
+	assert.Equal(t, "[Debug] started logger=test_pool size=2\n", buf.String())
+
+	buf.Reset()
+	SetModuleLevel("test_pool", LevelError)
+	SetLevel(LevelTrace)
+	pool.Warn("dropped")
+	Named("test_other").Info("kept")
+	assert.Equal(t, "[Info] kept logger=test_other\n", buf.String())
+
+	_, err := ParseLevelSpec("pool=verbose")
+	assert.NotNil(t, err)
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer rootLevel.Set(rootLevel.Level())
+	h := LevelHandler()
+
+	req := httptest.NewRequest(http.MethodPut, "/log/level?module=test_handler&level=error", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, LevelError, moduleLevel("test_handler").Level())
+
+	req = httptest.NewRequest(http.MethodPost, "/log/level", strings.NewReader(`{"*":"notice","test_handler":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var levels map[string]string
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &levels))
+	assert.Equal(t, "notice", levels["*"])
+	assert.Equal(t, "debug", levels["test_handler"])
+
+	req = httptest.NewRequest(http.MethodPut, "/log/level?level=loud", nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// out of range levels do not reset the module to inherit
+	req = httptest.NewRequest(http.MethodPost, "/log/level", strings.NewReader(`{"test_handler":-2147483648}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, LevelDebug, moduleLevel("test_handler").Level())
+}
+
+func TestDefaultLogger_Caller(t *testing.T) {
+	var buf bytes.Buffer
+	old := logger
+	defer SetLogger(old)
+	SetLogger(&defaultLogger{stdlog: log.New(&buf, "", log.Lshortfile), level: newLevelVar(LevelTrace)})
+
+	assertCaller := func(log func()) {
+		t.Helper()
+		buf.Reset()
+		_, file, line, _ := runtime.Caller(1)
+		log()
+		want := fmt.Sprintf("%s:%d: ", filepath.Base(file), line)
+		assert.True(t, strings.HasPrefix(buf.String(), want), "want %s, got %s", want, buf.String())
+	}
+
+	assertCaller(func() { Info("global") })
+	assertCaller(func() { Infof("global %d", 1) })
+	assertCaller(func() { Infow("global") })
+	assertCaller(func() { CtxInfof(context.Background(), "global") })
+	assertCaller(func() { DefaultLogger().Info("direct") })
+	assertCaller(func() { With("a", 1).Info("with") })
+	assertCaller(func() { With("a", 1).Infow("with") })
+	assertCaller(func() { Named("test_caller").Infof("named") })
+	assertCaller(func() { Named("test_caller").With("a", 1).CtxInfof(context.Background(), "named") })
+
+	SetEncoder(JSONEncoder{})
+	buf.Reset()
+	_, _, line, _ := runtime.Caller(0)
+	With("a", 1).Warnw("encoded")
+	assert.True(t, strings.Contains(buf.String(), fmt.Sprintf(`"caller":"default_test.go:%d"`, line+1)), buf.String())
+
+	buf.Reset()
+	_, _, line, _ = runtime.Caller(0)
+	Warnf("encoded %s", "global")
+	assert.True(t, strings.Contains(buf.String(), fmt.Sprintf(`"caller":"default_test.go:%d"`, line+1)), buf.String())
+}
+
+func TestLevel_JSON(t *testing.T) {
+	var cfg struct{ Level Level }
+	assert.Nil(t, json.Unmarshal([]byte(`{"Level":2}`), &cfg))
+	assert.Equal(t, LevelInfo, cfg.Level)
+	assert.Nil(t, json.Unmarshal([]byte(`{"Level":"warn"}`), &cfg))
+	assert.Equal(t, LevelWarn, cfg.Level)
+	assert.NotNil(t, json.Unmarshal([]byte(`{"Level":"loud"}`), &cfg))
+	assert.NotNil(t, json.Unmarshal([]byte(`{"Level":true}`), &cfg))
+	assert.NotNil(t, json.Unmarshal([]byte(`{"Level":7}`), &cfg))
+	assert.NotNil(t, json.Unmarshal([]byte(`{"Level":-2147483648}`), &cfg))
+
+	data, err := json.Marshal(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Level":4}`, string(data))
+
+	text, err := LevelError.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "error", string(text))
+}
@@ -0,0 +1,142 @@
+package hlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/joker-circus/gotools/bytebufferpool"
+)
+
+// Entry is a log entry passed to an Encoder.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Caller  string
+	Message string
+	Fields  []Field
+}
+
+// Encoder serializes log entries. The encoded entry must not end with a
+// newline, it is added by the logger.
+type Encoder interface {
+	Encode(buf *bytebufferpool.ByteBuffer, e *Entry) error
+}
+
+// The keys of the builtin fields of an encoded entry.
+const (
+	TimeKey    = "time"
+	LevelKey   = "level"
+	CallerKey  = "caller"
+	MessageKey = "msg"
+	LoggerKey  = "logger"
+)
+
+// JSONEncoder encodes entries as a single-line JSON object, e.g.
+//
+//	{"time":"2006-01-02T15:04:05.999999999Z07:00","level":"info","caller":"main.go:12","msg":"hello","user":"joker"}
+type JSONEncoder struct {
+	// TimeLayout is the layout of the time field, RFC3339Nano by default.
+	TimeLayout string
+}
+
+// Encode implements the Encoder interface.
+func (enc JSONEncoder) Encode(buf *bytebufferpool.ByteBuffer, e *Entry) error {
+	buf.WriteByte('{')
+	writeJSONKey(buf, TimeKey, true)
+	writeJSONValue(buf, e.Time.Format(timeLayout(enc.TimeLayout)))
+	writeJSONKey(buf, LevelKey, false)
+	writeJSONValue(buf, e.Level.String())
+	if e.Caller != "" {
+		writeJSONKey(buf, CallerKey, false)
+		writeJSONValue(buf, e.Caller)
+	}
+	writeJSONKey(buf, MessageKey, false)
+	writeJSONValue(buf, e.Message)
+	for _, f := range e.Fields {
+		writeJSONKey(buf, f.Key, false)
+		writeJSONValue(buf, fieldValue(f.Value))
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func writeJSONKey(buf *bytebufferpool.ByteBuffer, key string, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+	writeJSONValue(buf, key)
+	buf.WriteByte(':')
+}
+
+func writeJSONValue(buf *bytebufferpool.ByteBuffer, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(b)
+}
+
+// LogfmtEncoder encodes entries as logfmt key=value pairs, e.g.
+//
+//	time=2006-01-02T15:04:05.999999999Z07:00 level=info caller=main.go:12 msg=hello user=joker
+type LogfmtEncoder struct {
+	// TimeLayout is the layout of the time field, RFC3339Nano by default.
+	TimeLayout string
+}
+
+// Encode implements the Encoder interface.
+func (enc LogfmtEncoder) Encode(buf *bytebufferpool.ByteBuffer, e *Entry) error {
+	writeLogfmtPair(buf, TimeKey, e.Time.Format(timeLayout(enc.TimeLayout)), true)
+	writeLogfmtPair(buf, LevelKey, e.Level.String(), false)
+	if e.Caller != "" {
+		writeLogfmtPair(buf, CallerKey, e.Caller, false)
+	}
+	writeLogfmtPair(buf, MessageKey, e.Message, false)
+	writeLogfmtFields(buf, e.Fields)
+	return nil
+}
+
+// writeLogfmtFields appends fields as " key=value" pairs.
+func writeLogfmtFields(buf *bytebufferpool.ByteBuffer, fields []Field) {
+	for _, f := range fields {
+		writeLogfmtPair(buf, f.Key, f.Value, false)
+	}
+}
+
+func writeLogfmtPair(buf *bytebufferpool.ByteBuffer, key string, v interface{}, first bool) {
+	if !first {
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(logfmtQuote(key))
+	buf.WriteByte('=')
+	if v == nil {
+		buf.WriteString("null")
+		return
+	}
+	buf.WriteString(logfmtQuote(fmt.Sprint(fieldValue(v))))
+}
+
+// logfmtQuote quotes s if it is empty or contains spaces, quotes,
+// equal signs or unprintable characters.
+func logfmtQuote(s string) string {
+	if s == "" {
+		return `""`
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == unicode.ReplacementChar || !unicode.IsPrint(r)
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func timeLayout(layout string) string {
+	if layout == "" {
+		return time.RFC3339Nano
+	}
+	return layout
+}
@@ -0,0 +1,118 @@
+package hlog
+
+import "time"
+
+// badKey is the key used for arguments of Infow-style methods that are
+// not a string key followed by a value.
+const badKey = "!BADKEY"
+
+// Field is a key-value pair attached to a structured log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// String constructs a field with the given key and string value.
+func String(key, val string) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Int constructs a field with the given key and int value.
+func Int(key string, val int) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Int64 constructs a field with the given key and int64 value.
+func Int64(key string, val int64) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Uint64 constructs a field with the given key and uint64 value.
+func Uint64(key string, val uint64) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Float64 constructs a field with the given key and float64 value.
+func Float64(key string, val float64) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Bool constructs a field with the given key and bool value.
+func Bool(key string, val bool) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Duration constructs a field with the given key and duration value.
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Time constructs a field with the given key and time value.
+func Time(key string, val time.Time) Field {
+	return Field{Key: key, Value: val}
+}
+
+// Err constructs a field with the key "error" and the error message.
+// A nil error is kept as a nil value.
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: "error"}
+	}
+	return Field{Key: "error", Value: err.Error()}
+}
+
+// Any constructs a field with the given key and an arbitrary value.
+func Any(key string, val interface{}) Field {
+	return Field{Key: key, Value: val}
+}
+
+// toFields converts the arguments of Infow-style methods to fields.
+// Each argument is either a Field, or a string key followed by its value.
+// Arguments which do not fit are kept with the key "!BADKEY".
+func toFields(keysAndValues []interface{}) []Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+
+	fields := make([]Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i++ {
+		switch v := keysAndValues[i].(type) {
+		case Field:
+			fields = append(fields, v)
+		case string:
+			if i == len(keysAndValues)-1 {
+				fields = append(fields, Field{Key: badKey, Value: v})
+				break
+			}
+			fields = append(fields, Field{Key: v, Value: keysAndValues[i+1]})
+			i++
+		default:
+			fields = append(fields, Field{Key: badKey, Value: v})
+		}
+	}
+	return fields
+}
+
+// appendFields returns a new slice holding fields followed by more,
+// so that child loggers never share the backing array of their parent.
+func appendFields(fields []Field, more ...Field) []Field {
+	if len(more) == 0 {
+		return fields
+	}
+	res := make([]Field, 0, len(fields)+len(more))
+	res = append(res, fields...)
+	return append(res, more...)
+}
+
+// fieldValue converts the value to something readable by the encoders.
+func fieldValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case time.Duration:
+		return val.String()
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	}
+	return v
+}
@@ -0,0 +1,159 @@
+package hlog
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/joker-circus/gotools/bytebufferpool"
+)
+
+// fieldLoggerOf returns l itself if it is a FieldLogger, otherwise l is
+// wrapped so that the fields are appended to the message in logfmt.
+func fieldLoggerOf(l FullLogger) FieldLogger {
+	if fl, ok := l.(FieldLogger); ok {
+		return fl
+	}
+	return &fieldLogger{FullLogger: l}
+}
+
+// fieldLogger adapts a FullLogger set by SetLogger to FieldLogger.
+type fieldLogger struct {
+	FullLogger
+	fields []Field
+}
+
+func (l *fieldLogger) With(keysAndValues ...interface{}) FieldLogger {
+	return &fieldLogger{
+		FullLogger: l.FullLogger,
+		fields:     appendFields(l.fields, toFields(keysAndValues)...),
+	}
+}
+
+// message appends the fields to msg.
+func (l *fieldLogger) message(msg string, fields []Field) string {
+	if len(fields) == 0 {
+		return msg
+	}
+	buf := bytebufferpool.Get()
+	buf.WriteString(msg)
+	writeLogfmtFields(buf, fields)
+	msg = buf.String()
+	bytebufferpool.Put(buf)
+	return msg
+}
+
+func (l *fieldLogger) messagew(msg string, keysAndValues []interface{}) string {
+	return l.message(msg, appendFields(l.fields, toFields(keysAndValues)...))
+}
+
+func (l *fieldLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.FullLogger.Fatal(l.messagew(msg, keysAndValues))
+}
+
+func (l *fieldLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.FullLogger.Error(l.messagew(msg, keysAndValues))
+}
+
+func (l *fieldLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.FullLogger.Warn(l.messagew(msg, keysAndValues))
+}
+
+func (l *fieldLogger) Noticew(msg string, keysAndValues ...interface{}) {
+	l.FullLogger.Notice(l.messagew(msg, keysAndValues))
+}
+
+func (l *fieldLogger) Infow(msg string, keysAndValues ...interface{}) {
+	l.FullLogger.Info(l.messagew(msg, keysAndValues))
+}
+
+func (l *fieldLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.FullLogger.Debug(l.messagew(msg, keysAndValues))
+}
+
+func (l *fieldLogger) Tracew(msg string, keysAndValues ...interface{}) {
+	l.FullLogger.Trace(l.messagew(msg, keysAndValues))
+}
+
+func (l *fieldLogger) Fatal(v ...interface{}) {
+	l.FullLogger.Fatal(l.message(fmt.Sprint(v...), l.fields))
+}
+
+func (l *fieldLogger) Error(v ...interface{}) {
+	l.FullLogger.Error(l.message(fmt.Sprint(v...), l.fields))
+}
+
+func (l *fieldLogger) Warn(v ...interface{}) {
+	l.FullLogger.Warn(l.message(fmt.Sprint(v...), l.fields))
+}
+
+func (l *fieldLogger) Notice(v ...interface{}) {
+	l.FullLogger.Notice(l.message(fmt.Sprint(v...), l.fields))
+}
+
+func (l *fieldLogger) Info(v ...interface{}) {
+	l.FullLogger.Info(l.message(fmt.Sprint(v...), l.fields))
+}
+
+func (l *fieldLogger) Debug(v ...interface{}) {
+	l.FullLogger.Debug(l.message(fmt.Sprint(v...), l.fields))
+}
+
+func (l *fieldLogger) Trace(v ...interface{}) {
+	l.FullLogger.Trace(l.message(fmt.Sprint(v...), l.fields))
+}
+
+func (l *fieldLogger) Fatalf(format string, v ...interface{}) {
+	l.FullLogger.Fatal(l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) Errorf(format string, v ...interface{}) {
+	l.FullLogger.Error(l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) Warnf(format string, v ...interface{}) {
+	l.FullLogger.Warn(l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) Noticef(format string, v ...interface{}) {
+	l.FullLogger.Notice(l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) Infof(format string, v ...interface{}) {
+	l.FullLogger.Info(l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) Debugf(format string, v ...interface{}) {
+	l.FullLogger.Debug(l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) Tracef(format string, v ...interface{}) {
+	l.FullLogger.Trace(l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) CtxFatalf(ctx context.Context, format string, v ...interface{}) {
+	l.FullLogger.CtxFatalf(ctx, "%s", l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) CtxErrorf(ctx context.Context, format string, v ...interface{}) {
+	l.FullLogger.CtxErrorf(ctx, "%s", l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) CtxWarnf(ctx context.Context, format string, v ...interface{}) {
+	l.FullLogger.CtxWarnf(ctx, "%s", l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) CtxNoticef(ctx context.Context, format string, v ...interface{}) {
+	l.FullLogger.CtxNoticef(ctx, "%s", l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) CtxInfof(ctx context.Context, format string, v ...interface{}) {
+	l.FullLogger.CtxInfof(ctx, "%s", l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) CtxDebugf(ctx context.Context, format string, v ...interface{}) {
+	l.FullLogger.CtxDebugf(ctx, "%s", l.message(fmt.Sprintf(format, v...), l.fields))
+}
+
+func (l *fieldLogger) CtxTracef(ctx context.Context, format string, v ...interface{}) {
+	l.FullLogger.CtxTracef(ctx, "%s", l.message(fmt.Sprintf(format, v...), l.fields))
+}
@@ -0,0 +1,266 @@
+package hlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelEnv is the environment variable read at initialization to set the
+// levels of the default logger and the named loggers,
+// e.g. HLOG_LEVEL=gopool=debug,*=info.
+const LevelEnv = "HLOG_LEVEL"
+
+// rootModule is the module name of the default logger in level specs.
+const rootModule = "*"
+
+const levelUnset = math.MinInt32
+
+// levelVar is a level which can be changed atomically. An unset level
+// follows its parent.
+type levelVar struct {
+	v      atomic.Int32
+	parent *levelVar
+}
+
+func newLevelVar(lv Level) *levelVar {
+	v := &levelVar{}
+	v.v.Store(int32(lv))
+	return v
+}
+
+func (v *levelVar) Level() Level {
+	if lv := v.v.Load(); lv != levelUnset {
+		return Level(lv)
+	}
+	if v.parent != nil {
+		return v.parent.Level()
+	}
+	return LevelTrace
+}
+
+func (v *levelVar) Set(lv Level) {
+	v.v.Store(int32(lv))
+}
+
+// rootLevel is the level of the default logger, which is followed by
+// the named loggers without their own levels.
+var rootLevel = newLevelVar(LevelTrace)
+
+var modules = struct {
+	sync.Mutex
+	levels map[string]*levelVar
+}{levels: map[string]*levelVar{}}
+
+func init() {
+	if spec := os.Getenv(LevelEnv); spec != "" {
+		if err := SetLevels(spec); err != nil {
+			fmt.Fprintf(os.Stderr, "hlog: invalid %s: %v\n", LevelEnv, err)
+		}
+	}
+}
+
+// moduleLevel returns the level of the module, which is created on demand.
+func moduleLevel(name string) *levelVar {
+	modules.Lock()
+	defer modules.Unlock()
+
+	v, ok := modules.levels[name]
+	if !ok {
+		v = &levelVar{parent: rootLevel}
+		v.v.Store(levelUnset)
+		modules.levels[name] = v
+	}
+	return v
+}
+
+// Named returns a child of the default logger for the module, which has
+// its own level and adds the field "logger" to every log. Named loggers
+// without their own levels follow the level of the default logger.
+// If the logger set by SetLogger is not the default one, the per-module
+// levels take no effect.
+func Named(name string) FieldLogger {
+	if ll, ok := logger.(*defaultLogger); ok {
+		return ll.named(name)
+	}
+	return fieldLoggerOf(logger).With(LoggerKey, name)
+}
+
+// SetModuleLevel sets the level of the named loggers of the module.
+// The module "*" means the default logger. It is concurrent-safe.
+func SetModuleLevel(name string, lv Level) {
+	if name == rootModule {
+		rootLevel.Set(lv)
+		return
+	}
+	moduleLevel(name).Set(lv)
+}
+
+// ModuleLevels returns the levels of the default logger, keyed by "*",
+// and of all the known modules.
+func ModuleLevels() map[string]Level {
+	modules.Lock()
+	defer modules.Unlock()
+
+	levels := make(map[string]Level, len(modules.levels)+1)
+	levels[rootModule] = rootLevel.Level()
+	for name, v := range modules.levels {
+		levels[name] = v.Level()
+	}
+	return levels
+}
+
+// SetLevels sets the levels by a spec like "gopool=debug,*=info".
+// A level without module name, e.g. "info", is the level of the default logger.
+func SetLevels(spec string) error {
+	levels, err := ParseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+	for name, lv := range levels {
+		SetModuleLevel(name, lv)
+	}
+	return nil
+}
+
+// ParseLevelSpec parses a spec like "gopool=debug,*=info" to the levels
+// keyed by module names.
+func ParseLevelSpec(spec string) (map[string]Level, error) {
+	levels := map[string]Level{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, level := rootModule, item
+		if i := strings.LastIndexByte(item, '='); i >= 0 {
+			name, level = strings.TrimSpace(item[:i]), item[i+1:]
+		}
+		if name == "" {
+			return nil, fmt.Errorf("empty module name in %q", item)
+		}
+		lv, err := ParseLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		levels[name] = lv
+	}
+	return levels, nil
+}
+
+// ParseLevel parses a case-insensitive level name, e.g. "info" or "Warn".
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warning" {
+		return LevelWarn, nil
+	}
+	for i, name := range names {
+		if name == s {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+// MarshalJSON implements the json.Marshaler interface. Levels are encoded
+// as numbers, so that the existing JSON configs keep their format.
+func (lv Level) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(int(lv))), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting both
+// a number between LevelTrace and LevelFatal, e.g. 2, and a level name,
+// e.g. "info".
+func (lv *Level) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return lv.UnmarshalText([]byte(s))
+	}
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil || n < int(LevelTrace) || n > int(LevelFatal) {
+		return fmt.Errorf("invalid log level %s", data)
+	}
+	*lv = Level(n)
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (lv Level) MarshalText() ([]byte, error) {
+	return []byte(lv.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (lv *Level) UnmarshalText(text []byte) error {
+	l, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*lv = l
+	return nil
+}
+
+// LevelHandler returns an http.Handler to read and set the levels at runtime.
+// It should only be served on a local or admin address.
+//
+//	GET  returns the levels, e.g. {"*":"info","gopool":"debug"}
+//	PUT  or POST sets the levels by a JSON body in the same format, where
+//	     numeric levels are accepted too, or by the form values "module"
+//	     ("*" by default) and "level".
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			if err := setLevelsByRequest(r); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		levels := ModuleLevels()
+		names := make(map[string]string, len(levels))
+		for name, lv := range levels {
+			names[name] = lv.String()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(names)
+	})
+}
+
+func setLevelsByRequest(r *http.Request) error {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var levels map[string]Level
+		if err := json.NewDecoder(r.Body).Decode(&levels); err != nil {
+			return err
+		}
+		for name, lv := range levels {
+			SetModuleLevel(name, lv)
+		}
+		return nil
+	}
+
+	lv, err := ParseLevel(r.FormValue("level"))
+	if err != nil {
+		return err
+	}
+	name := r.FormValue("module")
+	if name == "" {
+		name = rootModule
+	}
+	SetModuleLevel(name, lv)
+	return nil
+}
@@ -40,6 +40,19 @@ type CtxLogger interface {
 	CtxFatalf(ctx context.Context, format string, v ...interface{})
 }
 
+// StructuredLogger is a logger interface that output logs with a message
+// and key-value pairs. Each of keysAndValues is either a Field, or a string
+// key followed by its value.
+type StructuredLogger interface {
+	Tracew(msg string, keysAndValues ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Noticew(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+	Fatalw(msg string, keysAndValues ...interface{})
+}
+
 // Control provides methods to config a logger.
 type Control interface {
 	SetLevel(Level)
@@ -54,6 +67,14 @@ type FullLogger interface {
 	Control
 }
 
+// FieldLogger is the combination of FullLogger and StructuredLogger.
+// With returns a child logger which adds the fields to every log.
+type FieldLogger interface {
+	FullLogger
+	StructuredLogger
+	With(keysAndValues ...interface{}) FieldLogger
+}
+
 // Level defines the priority of a log message.
 // When a logger is configured with a level, any log message with a lower
 // log level (smaller by integer comparison) will not be output.
@@ -80,6 +101,24 @@ var strs = []string{
 	"[Fatal] ",
 }
 
+var names = []string{
+	"trace",
+	"debug",
+	"info",
+	"notice",
+	"warn",
+	"error",
+	"fatal",
+}
+
+// String returns the lower-case name of the level, e.g. "info".
+func (lv Level) String() string {
+	if lv >= LevelTrace && lv <= LevelFatal {
+		return names[lv]
+	}
+	return fmt.Sprintf("?%d", lv)
+}
+
 func (lv Level) toString() string {
 	if lv >= LevelTrace && lv <= LevelFatal {
 		return strs[lv]
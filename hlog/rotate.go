@@ -0,0 +1,410 @@
+package hlog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/joker-circus/gotools/datatypes"
+)
+
+// RotateInterval defines how often a RotateWriter rotates by time.
+type RotateInterval int
+
+// The intervals of rotation.
+const (
+	RotateNone RotateInterval = iota
+	RotateHourly
+	RotateDaily
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+
+	// rotateRetryInterval is how long to wait before retrying a failed rotation.
+	rotateRetryInterval = time.Minute
+)
+
+// currentTime, openFile and renameFile are replaced in tests.
+var (
+	currentTime = time.Now
+	openFile    = os.OpenFile
+	renameFile  = os.Rename
+)
+
+// RotateOptions controls the behavior of RotateWriter.
+type RotateOptions struct {
+	// Filename is the file to write logs to. Backups are kept in the same
+	// directory, named like "app-2006-01-02T15-04-05.000.log".
+	Filename string
+
+	// MaxSize is the size in bytes beyond which the file is rotated.
+	// 0 means the file is not rotated by size.
+	MaxSize int64
+
+	// Interval rotates the file every hour or day.
+	Interval RotateInterval
+
+	// MaxAge is the max duration to keep backups. 0 keeps all backups.
+	MaxAge time.Duration
+
+	// MaxBackups is the max number of backups to keep. 0 keeps all backups.
+	MaxBackups int
+
+	// Compress gzips the backups.
+	Compress bool
+}
+
+// RotateWriter is an io.Writer writing logs to a file, which is rotated
+// by size and by time. It can be passed to SetOutput.
+type RotateWriter struct {
+	opt RotateOptions
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	next   time.Time
+	closed bool
+	// retry is the time before which a failed rotation is not retried.
+	retry time.Time
+
+	// mill runs the compression and the retention of backups.
+	millMu sync.Mutex
+	millWg sync.WaitGroup
+}
+
+// NewRotateWriter opens or creates opt.Filename and returns a RotateWriter.
+func NewRotateWriter(opt RotateOptions) (*RotateWriter, error) {
+	if opt.Filename == "" {
+		return nil, errors.New("rotate writer: filename is required")
+	}
+
+	w := &RotateWriter{opt: opt}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write implements io.Writer. If the file failed to be reopened by a
+// rotation, it is opened again on the next write. If the file failed to be
+// moved to a backup, p is still written to it, the error is reported to
+// stderr and the rotation is retried after a minute.
+func (w *RotateWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			if w.file == nil {
+				return 0, err
+			}
+			fmt.Fprintf(os.Stderr, "rotate writer: rotate %s failed: %v\n", w.opt.Filename, err)
+		}
+	}
+
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the file, moves it to a backup and creates a new file.
+func (w *RotateWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.rotate()
+}
+
+// Reopen closes and reopens the file, creating it if it has been moved,
+// e.g. by an external logrotate.
+func (w *RotateWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+	if err := w.close(); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// ReopenOnSignal reopens the file when one of sigs, SIGHUP by default,
+// is received. The returned function stops watching the signals.
+func (w *RotateWriter) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := w.Reopen(); err != nil {
+					fmt.Fprintf(os.Stderr, "rotate writer: reopen %s failed: %v\n", w.opt.Filename, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
+
+// Close closes the file and waits for the compression and the
+// retention of backups.
+func (w *RotateWriter) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	err := w.close()
+	w.mu.Unlock()
+
+	w.millWg.Wait()
+	return err
+}
+
+func (w *RotateWriter) shouldRotate(n int64) bool {
+	if currentTime().Before(w.retry) {
+		return false
+	}
+	if w.opt.MaxSize > 0 && w.size > 0 && w.size+n > w.opt.MaxSize {
+		return true
+	}
+	return w.opt.Interval != RotateNone && !currentTime().Before(w.next)
+}
+
+func (w *RotateWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.opt.Filename), 0755); err != nil {
+		return err
+	}
+
+	f, err := openFile(w.opt.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.file = f
+	w.size = info.Size()
+	// the data written before the current period is rotated on the next write
+	modTime := info.ModTime()
+	if now := currentTime(); info.Size() == 0 || modTime.After(now) {
+		modTime = now
+	}
+	w.next = w.nextRotateTime(modTime)
+	return nil
+}
+
+func (w *RotateWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// rotate moves the file to a backup and opens a new file. If the file
+// can not be moved, the old file is reopened to keep logging and the
+// rotation is retried after rotateRetryInterval; if the new file can not
+// be opened, it is opened again on the next write.
+func (w *RotateWriter) rotate() error {
+	if err := w.close(); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(w.opt.Filename); err == nil {
+		if err := renameFile(w.opt.Filename, w.backupName(currentTime())); err != nil {
+			if openErr := w.open(); openErr != nil {
+				return errors.Join(err, openErr)
+			}
+			w.retry = currentTime().Add(rotateRetryInterval)
+			return err
+		}
+	}
+	w.retry = time.Time{}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.millWg.Add(1)
+	go func() {
+		defer w.millWg.Done()
+		if err := w.mill(); err != nil {
+			fmt.Fprintf(os.Stderr, "rotate writer: clean backups of %s failed: %v\n", w.opt.Filename, err)
+		}
+	}()
+	return nil
+}
+
+func (w *RotateWriter) nextRotateTime(t time.Time) time.Time {
+	switch w.opt.Interval {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// prefixAndExt returns "app-" and ".log" for "app.log".
+func (w *RotateWriter) prefixAndExt() (prefix, ext string) {
+	name := filepath.Base(w.opt.Filename)
+	ext = filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-", ext
+}
+
+// backupName returns an unused backup name of t, a sequence number is
+// appended if the backups rotated in the same millisecond exist, e.g.
+// "app-2006-01-02T15-04-05.000-1.log".
+func (w *RotateWriter) backupName(t time.Time) string {
+	prefix, ext := w.prefixAndExt()
+	base := filepath.Join(filepath.Dir(w.opt.Filename), prefix+t.Local().Format(backupTimeFormat))
+	name := base + ext
+	for seq := 1; fileExists(name) || fileExists(name+compressSuffix); seq++ {
+		name = base + "-" + strconv.Itoa(seq) + ext
+	}
+	return name
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+type backupFile struct {
+	path string
+	t    time.Time
+	seq  int
+}
+
+// backups returns the backups of the file, the newest first.
+func (w *RotateWriter) backups() ([]backupFile, error) {
+	dir := filepath.Dir(w.opt.Filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, ext := w.prefixAndExt()
+	var files []backupFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), compressSuffix)
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := name[len(prefix) : len(name)-len(ext)]
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		var seq int
+		if suffix := stamp[len(backupTimeFormat):]; suffix != "" {
+			if seq, err = strconv.Atoi(strings.TrimPrefix(suffix, "-")); err != nil || suffix[0] != '-' || seq <= 0 {
+				continue
+			}
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, stamp[:len(backupTimeFormat)], time.Local)
+		if err != nil {
+			continue
+		}
+		files = append(files, backupFile{path: filepath.Join(dir, e.Name()), t: t, seq: seq})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].t.Equal(files[j].t) {
+			return files[i].t.After(files[j].t)
+		}
+		return files[i].seq > files[j].seq
+	})
+	return files, nil
+}
+
+// mill compresses the backups and removes the expired ones.
+func (w *RotateWriter) mill() error {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	files, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	var cutoff time.Time
+	if w.opt.MaxAge > 0 {
+		cutoff = currentTime().Add(-w.opt.MaxAge)
+	}
+
+	for i, f := range files {
+		if (w.opt.MaxBackups > 0 && i >= w.opt.MaxBackups) || (!cutoff.IsZero() && f.t.Before(cutoff)) {
+			if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+
+		if w.opt.Compress && !strings.HasSuffix(f.path, compressSuffix) {
+			if err := compressFile(f.path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compressFile gzips the file to path+".gz" and removes the file.
+func compressFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	zipped, err := datatypes.Gzip(data)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+compressSuffix, zipped, info.Mode()); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,197 @@
+package hlog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/joker-circus/gotools/datatypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockCurrentTime(t *testing.T, now *time.Time) {
+	currentTime = func() time.Time { return *now }
+	t.Cleanup(func() { currentTime = time.Now })
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotateWriter_MaxSize(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	mockCurrentTime(t, &now)
+
+	w, err := NewRotateWriter(RotateOptions{
+		Filename:   filepath.Join(dir, "app.log"),
+		MaxSize:    10,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+	assert.Nil(t, err)
+
+	for i := 0; i < 4; i++ {
+		now = now.Add(time.Second)
+		_, err = w.Write([]byte("0123456789"))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, []string{
+		"app-2022-01-01T10-00-03.000.log.gz",
+		"app-2022-01-01T10-00-04.000.log.gz",
+		"app.log",
+	}, dirNames(t, dir))
+
+	zipped, err := os.ReadFile(filepath.Join(dir, "app-2022-01-01T10-00-04.000.log.gz"))
+	assert.Nil(t, err)
+	data, err := datatypes.UnGzip(zipped)
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789", string(data))
+}
+
+func TestRotateWriter_Interval(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 1, 1, 10, 30, 0, 0, time.Local)
+	mockCurrentTime(t, &now)
+
+	w, err := NewRotateWriter(RotateOptions{
+		Filename: filepath.Join(dir, "app.log"),
+		Interval: RotateHourly,
+	})
+	assert.Nil(t, err)
+
+	w.Write([]byte("a"))
+	now = now.Add(20 * time.Minute)
+	w.Write([]byte("b"))
+	now = now.Add(20 * time.Minute)
+	w.Write([]byte("c"))
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, []string{"app-2022-01-01T11-10-00.000.log", "app.log"}, dirNames(t, dir))
+	data, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	assert.Equal(t, "c", string(data))
+}
+
+func TestRotateWriter_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := NewRotateWriter(RotateOptions{Filename: filename})
+	assert.Nil(t, err)
+	w.Write([]byte("a"))
+
+	// moved by an external logrotate
+	assert.Nil(t, os.Rename(filename, filename+".1"))
+	assert.Nil(t, w.Reopen())
+	w.Write([]byte("b"))
+	assert.Nil(t, w.Close())
+
+	data, _ := os.ReadFile(filename)
+	assert.Equal(t, "b", string(data))
+	_, err = w.Write([]byte("c"))
+	assert.Equal(t, os.ErrClosed, err)
+}
+
+func TestRotateWriter_SameMillisecond(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	mockCurrentTime(t, &now)
+
+	w, err := NewRotateWriter(RotateOptions{Filename: filepath.Join(dir, "app.log"), MaxBackups: 2})
+	assert.Nil(t, err)
+	for _, s := range []string{"a", "b", "c", "d"} {
+		w.Write([]byte(s))
+		assert.Nil(t, w.Rotate())
+	}
+	assert.Nil(t, w.Close())
+
+	assert.Equal(t, []string{
+		"app-2022-01-01T10-00-00.000-2.log",
+		"app-2022-01-01T10-00-00.000-3.log",
+		"app.log",
+	}, dirNames(t, dir))
+	data, _ := os.ReadFile(filepath.Join(dir, "app-2022-01-01T10-00-00.000-3.log"))
+	assert.Equal(t, "d", string(data))
+}
+
+func TestRotateWriter_OpenFailed(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+
+	failed := errors.New("no space left on device")
+	failures := 0
+	openFile = func(name string, flag int, perm os.FileMode) (*os.File, error) {
+		if failures > 0 {
+			failures--
+			return nil, failed
+		}
+		return os.OpenFile(name, flag, perm)
+	}
+	t.Cleanup(func() { openFile = os.OpenFile })
+
+	w, err := NewRotateWriter(RotateOptions{Filename: filename})
+	assert.Nil(t, err)
+	w.Write([]byte("a"))
+
+	failures = 2
+	assert.Equal(t, failed, w.Rotate())
+	_, err = w.Write([]byte("b"))
+	assert.Equal(t, failed, err)
+	_, err = w.Write([]byte("c"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	data, _ := os.ReadFile(filename)
+	assert.Equal(t, "c", string(data))
+	_, err = w.Write([]byte("d"))
+	assert.Equal(t, os.ErrClosed, err)
+}
+
+func TestRotateWriter_RenameFailed(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "app.log")
+	now := time.Date(2022, 1, 1, 10, 0, 0, 0, time.Local)
+	mockCurrentTime(t, &now)
+
+	failed := errors.New("invalid cross-device link")
+	renames := 0
+	renameFile = func(oldpath, newpath string) error {
+		renames++
+		return failed
+	}
+	t.Cleanup(func() { renameFile = os.Rename })
+
+	w, err := NewRotateWriter(RotateOptions{Filename: filename, MaxSize: 2})
+	assert.Nil(t, err)
+
+	for _, s := range []string{"ab", "cd", "ef"} {
+		n, err := w.Write([]byte(s))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, n)
+	}
+	assert.Equal(t, 1, renames)
+
+	// retried after rotateRetryInterval
+	now = now.Add(rotateRetryInterval)
+	renameFile = os.Rename
+	_, err = w.Write([]byte("gh"))
+	assert.Nil(t, err)
+	assert.Nil(t, w.Close())
+
+	data, _ := os.ReadFile(filename)
+	assert.Equal(t, "gh", string(data))
+	data, _ = os.ReadFile(filepath.Join(dir, "app-2022-01-01T10-01-00.000.log"))
+	assert.Equal(t, "abcdef", string(data))
+}
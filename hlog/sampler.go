@@ -0,0 +1,97 @@
+package hlog
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Sampler decides whether a log is output, so that noisy loops don't flood
+// the disk. The key is the format of printf-style logs, or the message of
+// the others. Fatal logs are always output, they are not passed to the
+// Sampler.
+type Sampler interface {
+	Sample(lv Level, key string) bool
+}
+
+const samplerBuckets = 4096
+
+// countSampler outputs the first logs of each key in every tick, and then
+// one of every thereafter logs.
+type countSampler struct {
+	first      uint64
+	thereafter uint64
+	tick       time.Duration
+	counters   [samplerBuckets]sampleCounter
+}
+
+// NewRateSampler returns a Sampler which outputs at most limit logs of
+// each key in every interval, or in total if interval <= 0.
+func NewRateSampler(limit int, interval time.Duration) Sampler {
+	return NewCountSampler(limit, 0, interval)
+}
+
+// NewCountSampler returns a Sampler which outputs the first logs of each
+// key in every tick, and then one of every thereafter logs. Logs beyond
+// first are dropped if thereafter is 0. The counters are never reset if
+// tick <= 0, i.e. only the first logs of each key are output in total.
+// Keys are hashed into a fixed number of buckets, so rare collisions
+// of keys share their counters.
+func NewCountSampler(first, thereafter int, tick time.Duration) Sampler {
+	if first < 0 {
+		first = 0
+	}
+	if thereafter < 0 {
+		thereafter = 0
+	}
+	return &countSampler{
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		tick:       tick,
+	}
+}
+
+func (s *countSampler) Sample(lv Level, key string) bool {
+	c := &s.counters[fnv32a(lv, key)%samplerBuckets]
+	n := c.inc(currentTime().UnixNano(), s.tick.Nanoseconds())
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
+
+type sampleCounter struct {
+	resetAt atomic.Int64
+	n       atomic.Uint64
+}
+
+// inc increases the counter and returns the count in current tick.
+func (c *sampleCounter) inc(now, tick int64) uint64 {
+	resetAt := c.resetAt.Load()
+	if tick <= 0 || resetAt > now {
+		return c.n.Add(1)
+	}
+
+	// only the goroutine moving the tick forward resets the count, the
+	// others count into the new tick
+	if c.resetAt.CompareAndSwap(resetAt, now+tick) {
+		c.n.Store(1)
+		return 1
+	}
+	return c.n.Add(1)
+}
+
+// fnv32a hashes the level and key without allocation.
+func fnv32a(lv Level, key string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	h ^= uint32(lv)
+	h *= prime32
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= prime32
+	}
+	return h
+}
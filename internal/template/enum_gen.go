@@ -0,0 +1,463 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// EnumValue 为枚举的一个值
+type EnumValue struct {
+	// Name 为 Go 常量名，如 StatusActive
+	Name string
+	// Text 为 String、JSON、文本使用的字符串，如 active，为空时为去掉类型名前缀的 snake_case
+	Text string
+	// Label 为中文展示名，如 启用，可为空
+	Label string
+}
+
+// EnumSpec 为枚举的定义
+type EnumSpec struct {
+	// Package 为生成文件的包名，为空时为生成文件所在目录名
+	Package string
+	// TypeName 为枚举类型名，如 Status
+	TypeName string
+	// BaseType 为枚举的基础类型，如 int、int8、uint、string，默认 int
+	BaseType string
+	// DeclareConst 为 true 时生成类型及常量定义，整数类型从 0 开始使用 iota，string 类型的值为 Text。
+	// 从已有的常量生成时为 false。
+	DeclareConst bool
+	// SQLText 为 true 时 SQL 存储 Text，否则存储基础类型的值
+	SQLText bool
+	Values  []EnumValue
+}
+
+func (s *EnumSpec) normalize() error {
+	if s.TypeName == "" {
+		return errors.New("enum type name is empty")
+	}
+	if len(s.Values) == 0 {
+		return fmt.Errorf("enum %s has no values", s.TypeName)
+	}
+	if s.BaseType == "" {
+		s.BaseType = "int"
+	}
+
+	texts := make(map[string]string, len(s.Values))
+	for i := range s.Values {
+		v := &s.Values[i]
+		if v.Text == "" {
+			v.Text = enumText(s.TypeName, v.Name)
+		}
+		if name, ok := texts[v.Text]; ok {
+			return fmt.Errorf("enum %s: %s and %s have the same text %q", s.TypeName, name, v.Name, v.Text)
+		}
+		texts[v.Text] = v.Name
+	}
+	return nil
+}
+
+// IsString 基础类型是否为 string
+func (s EnumSpec) IsString() bool {
+	return s.BaseType == "string"
+}
+
+// Zero 基础类型的零值
+func (s EnumSpec) Zero() string {
+	if s.IsString() {
+		return `""`
+	}
+	return "0"
+}
+
+// IsUnsigned 基础类型是否为无符号整数
+func (s EnumSpec) IsUnsigned() bool {
+	return strings.HasPrefix(s.BaseType, "uint")
+}
+
+// IsWideUnsigned 基础类型是否可能超出 int64 的取值范围
+func (s EnumSpec) IsWideUnsigned() bool {
+	return s.BaseType == "uint" || s.BaseType == "uint64" || s.BaseType == "uintptr"
+}
+
+// HasLabel 是否有中文展示名
+func (s EnumSpec) HasLabel() bool {
+	for _, v := range s.Values {
+		if v.Label != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// enumText StatusInReview => in_review
+func enumText(typeName, name string) string {
+	name = strings.TrimPrefix(name, typeName)
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// EnumGen 根据 spec 生成枚举代码，fileName 为生成的文件名，在调用该方法的包下。
+// 生成 String、Parse<Type>、<Type>Values、IsValid、JSON、文本及 SQL 的序列化方法，
+// 有中文展示名时生成 CN 方法。
+// Usage:
+//
+//	func TestEnumGen(t *testing.T) {
+//		template.EnumGen(template.EnumSpec{
+//			TypeName:     "Status",
+//			DeclareConst: true,
+//			Values: []template.EnumValue{
+//				{Name: "StatusActive", Label: "启用"},
+//				{Name: "StatusDisabled", Label: "禁用"},
+//			},
+//		}, "status_enum.go")
+//	}
+func EnumGen(spec EnumSpec, fileName string) {
+	_, file, _, ok := runtime.Caller(1)
+	if !ok {
+		panic("runtime.Caller(1) fail")
+	}
+
+	workDir := path.Dir(file)
+	if spec.Package == "" {
+		spec.Package = path.Base(workDir)
+	}
+	writeEnum(spec, path.Join(workDir, fileName))
+}
+
+// EnumGenFromSource 根据调用方包下 sourceName 文件中 typeName 类型的常量生成枚举代码，
+// fileName 为生成的文件名。
+// 常量的行尾注释可以使用 tag 格式指定 Text 及中文展示名：
+//
+//	type Status int8
+//
+//	const (
+//		StatusActive   Status = iota + 1 // enum:"active" cn:"启用"
+//		StatusDisabled                   // cn:"禁用"
+//	)
+func EnumGenFromSource(sourceName, typeName, fileName string) {
+	_, file, _, ok := runtime.Caller(1)
+	if !ok {
+		panic("runtime.Caller(1) fail")
+	}
+
+	workDir := path.Dir(file)
+	src, err := os.ReadFile(path.Join(workDir, sourceName))
+	if err != nil {
+		panic(err)
+	}
+	spec, err := ParseEnumSpec(src, typeName)
+	if err != nil {
+		panic(err)
+	}
+	writeEnum(spec, path.Join(workDir, fileName))
+}
+
+func writeEnum(spec EnumSpec, filename string) {
+	out, err := RenderEnum(spec)
+	if err != nil {
+		panic(err)
+	}
+	if err = os.WriteFile(filename, out, 0660); err != nil {
+		panic(err)
+	}
+}
+
+// ParseEnumSpec 解析 Go 源码中 typeName 类型及其常量，用于 RenderEnum。
+func ParseEnumSpec(src []byte, typeName string) (EnumSpec, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return EnumSpec{}, err
+	}
+
+	spec := EnumSpec{Package: f.Name.Name, TypeName: typeName}
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+
+		switch gen.Tok {
+		case token.TYPE:
+			for _, s := range gen.Specs {
+				ts := s.(*ast.TypeSpec)
+				if ident, ok := ts.Type.(*ast.Ident); ok && ts.Name.Name == typeName {
+					spec.BaseType = ident.Name
+				}
+			}
+		case token.CONST:
+			// 省略类型的常量沿用上一个常量的类型（iota 的写法）
+			var inEnum bool
+			for _, s := range gen.Specs {
+				vs := s.(*ast.ValueSpec)
+				if vs.Type != nil {
+					ident, ok := vs.Type.(*ast.Ident)
+					inEnum = ok && ident.Name == typeName
+				} else if len(vs.Values) > 0 {
+					inEnum = false
+				}
+				if !inEnum {
+					continue
+				}
+
+				var tag reflect.StructTag
+				if vs.Comment != nil {
+					tag = reflect.StructTag(strings.TrimSpace(vs.Comment.Text()))
+				}
+				for _, name := range vs.Names {
+					if name.Name == "_" {
+						continue
+					}
+					spec.Values = append(spec.Values, EnumValue{
+						Name:  name.Name,
+						Text:  tag.Get("enum"),
+						Label: tag.Get("cn"),
+					})
+				}
+			}
+		}
+	}
+
+	if spec.BaseType == "" {
+		return spec, fmt.Errorf("type %s not found", typeName)
+	}
+	return spec, nil
+}
+
+// RenderEnum 生成枚举代码
+func RenderEnum(spec EnumSpec) ([]byte, error) {
+	if err := spec.normalize(); err != nil {
+		return nil, err
+	}
+
+	var w bytes.Buffer
+	tmpl := template.Must(template.New("enum").Funcs(template.FuncMap{"quote": strconv.Quote}).Parse(enumTemplate))
+	if err := tmpl.Execute(&w, spec); err != nil {
+		return nil, err
+	}
+	return format.Source(w.Bytes())
+}
+
+const enumTemplate = `package {{.Package}}
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+)
+{{$t := .TypeName}}
+{{- if .DeclareConst}}
+type {{$t}} {{.BaseType}}
+
+const (
+{{- range $i, $v := .Values}}
+	{{- if $.IsString}}
+	{{$v.Name}} {{$t}} = {{quote $v.Text}}
+	{{- else if eq $i 0}}
+	{{$v.Name}} {{$t}} = iota
+	{{- else}}
+	{{$v.Name}}
+	{{- end}}
+{{- end}}
+)
+{{- end}}
+
+// ErrInvalid{{$t}} 为无效的 {{$t}}
+var ErrInvalid{{$t}} = errors.New("invalid {{$t}}")
+
+var _{{$t}}Values = []{{$t}}{
+{{- range .Values}}
+	{{.Name}},
+{{- end}}
+}
+
+var _{{$t}}Texts = map[{{$t}}]string{
+{{- range .Values}}
+	{{.Name}}: {{quote .Text}},
+{{- end}}
+}
+
+var _{{$t}}Parse = map[string]{{$t}}{
+{{- range .Values}}
+	{{quote .Text}}: {{.Name}},
+{{- end}}
+}
+{{- if .HasLabel}}
+
+var _{{$t}}Labels = map[{{$t}}]string{
+{{- range .Values}}
+	{{.Name}}: {{quote .Label}},
+{{- end}}
+}
+{{- end}}
+
+// {{$t}}Values 返回 {{$t}} 的全部值
+func {{$t}}Values() []{{$t}} {
+	return append([]{{$t}}(nil), _{{$t}}Values...)
+}
+
+// Parse{{$t}} 解析 {{$t}} 的字符串
+{{- if not .IsString}}，也可以是数值{{end}}
+func Parse{{$t}}(s string) ({{$t}}, error) {
+	if x, ok := _{{$t}}Parse[s]; ok {
+		return x, nil
+	}
+{{- if not .IsString}}
+{{- if .IsUnsigned}}
+	if n, err := strconv.ParseUint(s, 10, 64); err == nil && uint64({{$t}}(n)) == n && {{$t}}(n).IsValid() {
+		return {{$t}}(n), nil
+	}
+{{- else}}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && int64({{$t}}(n)) == n && {{$t}}(n).IsValid() {
+		return {{$t}}(n), nil
+	}
+{{- end}}
+{{- end}}
+	return {{$t}}({{.Zero}}), fmt.Errorf("%w: %q", ErrInvalid{{$t}}, s)
+}
+
+// IsValid 是否为定义的 {{$t}}
+func (x {{$t}}) IsValid() bool {
+	_, ok := _{{$t}}Texts[x]
+	return ok
+}
+
+func (x {{$t}}) String() string {
+	if s, ok := _{{$t}}Texts[x]; ok {
+		return s
+	}
+	return fmt.Sprintf("{{$t}}(%{{if .IsString}}q{{else}}d{{end}})", {{.BaseType}}(x))
+}
+{{- if .HasLabel}}
+
+// CN 返回中文展示名
+func (x {{$t}}) CN() string {
+	if s, ok := _{{$t}}Labels[x]; ok {
+		return s
+	}
+	return x.String()
+}
+{{- end}}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (x {{$t}}) MarshalText() ([]byte, error) {
+	if !x.IsValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid{{$t}}, x)
+	}
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (x *{{$t}}) UnmarshalText(text []byte) error {
+	v, err := Parse{{$t}}(string(text))
+	if err != nil {
+		return err
+	}
+	*x = v
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, the zero value is encoded as null if it is not defined.
+func (x {{$t}}) MarshalJSON() ([]byte, error) {
+	if x == {{$t}}({{.Zero}}) && !x.IsValid() {
+		return []byte("null"), nil
+	}
+	b, err := x.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strconv.Quote(string(b))), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+{{- if not .IsString}}, accepting the text and the number{{end}}.
+func (x *{{$t}}) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*x = {{$t}}({{.Zero}})
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return err
+		}
+	}
+	return x.UnmarshalText([]byte(s))
+}
+
+// Value implements the driver.Valuer interface, the zero value is stored as NULL if it is not defined.
+func (x {{$t}}) Value() (driver.Value, error) {
+	if x == {{$t}}({{.Zero}}) && !x.IsValid() {
+		return nil, nil
+	}
+	if !x.IsValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid{{$t}}, x)
+	}
+{{- if or .SQLText .IsString}}
+	return x.String(), nil
+{{- else if .IsWideUnsigned}}
+	// driver.Value has no uint64, values beyond int64 are stored as decimal text.
+	if uint64(x) > 1<<63-1 {
+		return strconv.FormatUint(uint64(x), 10), nil
+	}
+	return int64(x), nil
+{{- else}}
+	return int64(x), nil
+{{- end}}
+}
+
+// Scan implements the sql.Scanner interface.
+func (x *{{$t}}) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		*x = {{$t}}({{.Zero}})
+		return nil
+	case []byte:
+		return x.UnmarshalText(value)
+	case string:
+		return x.UnmarshalText([]byte(value))
+{{- if not .IsString}}
+	case int64:
+		if {{if .IsUnsigned}}value < 0 || {{end}}int64({{$t}}(value)) != value || !{{$t}}(value).IsValid() {
+			return fmt.Errorf("%w: %d", ErrInvalid{{$t}}, value)
+		}
+		*x = {{$t}}(value)
+		return nil
+{{- end}}
+{{- if .IsUnsigned}}
+	case uint64:
+		if uint64({{$t}}(value)) != value || !{{$t}}(value).IsValid() {
+			return fmt.Errorf("%w: %d", ErrInvalid{{$t}}, value)
+		}
+		*x = {{$t}}(value)
+		return nil
+{{- end}}
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to {{$t}}", v, v)
+	}
+}
+`
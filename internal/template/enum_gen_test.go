@@ -0,0 +1,56 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnumSpec(t *testing.T) {
+	src := `package order
+
+type Level uint8
+
+const (
+	LevelLow Level = iota + 1 // cn:"低"
+	LevelHigh                 // enum:"hi" cn:"高"
+	_
+	other = 1
+)
+`
+	spec, err := ParseEnumSpec([]byte(src), "Level")
+	assert.Nil(t, err)
+	assert.Equal(t, EnumSpec{
+		Package:  "order",
+		TypeName: "Level",
+		BaseType: "uint8",
+		Values: []EnumValue{
+			{Name: "LevelLow", Label: "低"},
+			{Name: "LevelHigh", Text: "hi", Label: "高"},
+		},
+	}, spec)
+
+	out, err := RenderEnum(spec)
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), `LevelLow:  "low",`)
+	assert.Contains(t, string(out), "strconv.ParseUint(s, 10, 64)")
+	assert.Contains(t, string(out), "if value < 0 || int64(Level(value)) != value")
+	assert.NotContains(t, string(out), "strconv.FormatUint")
+
+	spec.BaseType = "uint64"
+	out, err = RenderEnum(spec)
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "return strconv.FormatUint(uint64(x), 10), nil")
+	assert.Contains(t, string(out), "case uint64:")
+
+	_, err = ParseEnumSpec([]byte(src), "Missing")
+	assert.NotNil(t, err)
+
+	_, err = RenderEnum(EnumSpec{TypeName: "Dup", Values: []EnumValue{{Name: "DupA", Text: "a"}, {Name: "DupB", Text: "a"}}})
+	assert.NotNil(t, err)
+}
+
+func TestEnumText(t *testing.T) {
+	assert.Equal(t, "in_review", enumText("Status", "StatusInReview"))
+	assert.Equal(t, "active", enumText("Status", "StatusActive"))
+}
@@ -0,0 +1,144 @@
+package example
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+type Color string
+
+const (
+	ColorRed       Color = "red"
+	ColorLightBlue Color = "light_blue"
+)
+
+// ErrInvalidColor 为无效的 Color
+var ErrInvalidColor = errors.New("invalid Color")
+
+var _ColorValues = []Color{
+	ColorRed,
+	ColorLightBlue,
+}
+
+var _ColorTexts = map[Color]string{
+	ColorRed:       "red",
+	ColorLightBlue: "light_blue",
+}
+
+var _ColorParse = map[string]Color{
+	"red":        ColorRed,
+	"light_blue": ColorLightBlue,
+}
+
+var _ColorLabels = map[Color]string{
+	ColorRed:       "红",
+	ColorLightBlue: "浅蓝",
+}
+
+// ColorValues 返回 Color 的全部值
+func ColorValues() []Color {
+	return append([]Color(nil), _ColorValues...)
+}
+
+// ParseColor 解析 Color 的字符串
+func ParseColor(s string) (Color, error) {
+	if x, ok := _ColorParse[s]; ok {
+		return x, nil
+	}
+	return Color(""), fmt.Errorf("%w: %q", ErrInvalidColor, s)
+}
+
+// IsValid 是否为定义的 Color
+func (x Color) IsValid() bool {
+	_, ok := _ColorTexts[x]
+	return ok
+}
+
+func (x Color) String() string {
+	if s, ok := _ColorTexts[x]; ok {
+		return s
+	}
+	return fmt.Sprintf("Color(%q)", string(x))
+}
+
+// CN 返回中文展示名
+func (x Color) CN() string {
+	if s, ok := _ColorLabels[x]; ok {
+		return s
+	}
+	return x.String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (x Color) MarshalText() ([]byte, error) {
+	if !x.IsValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidColor, x)
+	}
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (x *Color) UnmarshalText(text []byte) error {
+	v, err := ParseColor(string(text))
+	if err != nil {
+		return err
+	}
+	*x = v
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, the zero value is encoded as null if it is not defined.
+func (x Color) MarshalJSON() ([]byte, error) {
+	if x == Color("") && !x.IsValid() {
+		return []byte("null"), nil
+	}
+	b, err := x.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strconv.Quote(string(b))), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (x *Color) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*x = Color("")
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return err
+		}
+	}
+	return x.UnmarshalText([]byte(s))
+}
+
+// Value implements the driver.Valuer interface, the zero value is stored as NULL if it is not defined.
+func (x Color) Value() (driver.Value, error) {
+	if x == Color("") && !x.IsValid() {
+		return nil, nil
+	}
+	if !x.IsValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidColor, x)
+	}
+	return x.String(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (x *Color) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		*x = Color("")
+		return nil
+	case []byte:
+		return x.UnmarshalText(value)
+	case string:
+		return x.UnmarshalText([]byte(value))
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to Color", v, v)
+	}
+}
@@ -0,0 +1,23 @@
+package example
+
+import (
+	"testing"
+
+	"github.com/joker-circus/gotools/internal/template"
+)
+
+func TestStatusEnumGen(t *testing.T) {
+	template.EnumGenFromSource("status.go", "Status", "status_enum.go")
+}
+
+func TestColorEnumGen(t *testing.T) {
+	template.EnumGen(template.EnumSpec{
+		TypeName:     "Color",
+		BaseType:     "string",
+		DeclareConst: true,
+		Values: []template.EnumValue{
+			{Name: "ColorRed", Label: "红"},
+			{Name: "ColorLightBlue", Label: "浅蓝"},
+		},
+	}, "color_enum.go")
+}
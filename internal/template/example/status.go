@@ -0,0 +1,10 @@
+package example
+
+// Status 为 EnumGenFromSource 的示例
+type Status int8
+
+const (
+	StatusActive   Status = iota + 1 // cn:"启用"
+	StatusDisabled                   // cn:"禁用"
+	StatusInReview                   // enum:"review" cn:"审核中"
+)
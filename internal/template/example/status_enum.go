@@ -0,0 +1,150 @@
+package example
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrInvalidStatus 为无效的 Status
+var ErrInvalidStatus = errors.New("invalid Status")
+
+var _StatusValues = []Status{
+	StatusActive,
+	StatusDisabled,
+	StatusInReview,
+}
+
+var _StatusTexts = map[Status]string{
+	StatusActive:   "active",
+	StatusDisabled: "disabled",
+	StatusInReview: "review",
+}
+
+var _StatusParse = map[string]Status{
+	"active":   StatusActive,
+	"disabled": StatusDisabled,
+	"review":   StatusInReview,
+}
+
+var _StatusLabels = map[Status]string{
+	StatusActive:   "启用",
+	StatusDisabled: "禁用",
+	StatusInReview: "审核中",
+}
+
+// StatusValues 返回 Status 的全部值
+func StatusValues() []Status {
+	return append([]Status(nil), _StatusValues...)
+}
+
+// ParseStatus 解析 Status 的字符串，也可以是数值
+func ParseStatus(s string) (Status, error) {
+	if x, ok := _StatusParse[s]; ok {
+		return x, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && int64(Status(n)) == n && Status(n).IsValid() {
+		return Status(n), nil
+	}
+	return Status(0), fmt.Errorf("%w: %q", ErrInvalidStatus, s)
+}
+
+// IsValid 是否为定义的 Status
+func (x Status) IsValid() bool {
+	_, ok := _StatusTexts[x]
+	return ok
+}
+
+func (x Status) String() string {
+	if s, ok := _StatusTexts[x]; ok {
+		return s
+	}
+	return fmt.Sprintf("Status(%d)", int8(x))
+}
+
+// CN 返回中文展示名
+func (x Status) CN() string {
+	if s, ok := _StatusLabels[x]; ok {
+		return s
+	}
+	return x.String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (x Status) MarshalText() ([]byte, error) {
+	if !x.IsValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidStatus, x)
+	}
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (x *Status) UnmarshalText(text []byte) error {
+	v, err := ParseStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*x = v
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface, the zero value is encoded as null if it is not defined.
+func (x Status) MarshalJSON() ([]byte, error) {
+	if x == Status(0) && !x.IsValid() {
+		return []byte("null"), nil
+	}
+	b, err := x.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strconv.Quote(string(b))), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting the text and the number.
+func (x *Status) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*x = Status(0)
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return err
+		}
+	}
+	return x.UnmarshalText([]byte(s))
+}
+
+// Value implements the driver.Valuer interface, the zero value is stored as NULL if it is not defined.
+func (x Status) Value() (driver.Value, error) {
+	if x == Status(0) && !x.IsValid() {
+		return nil, nil
+	}
+	if !x.IsValid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidStatus, x)
+	}
+	return int64(x), nil
+}
+
+// Scan implements the sql.Scanner interface.
+func (x *Status) Scan(v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		*x = Status(0)
+		return nil
+	case []byte:
+		return x.UnmarshalText(value)
+	case string:
+		return x.UnmarshalText([]byte(value))
+	case int64:
+		if int64(Status(value)) != value || !Status(value).IsValid() {
+			return fmt.Errorf("%w: %d", ErrInvalidStatus, value)
+		}
+		*x = Status(value)
+		return nil
+	default:
+		return fmt.Errorf("can not scan value %v (%T) to Status", v, v)
+	}
+}
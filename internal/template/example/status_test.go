@@ -0,0 +1,65 @@
+package example
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	assert.Equal(t, []Status{StatusActive, StatusDisabled, StatusInReview}, StatusValues())
+	assert.Equal(t, "review", StatusInReview.String())
+	assert.Equal(t, "审核中", StatusInReview.CN())
+	assert.Equal(t, "Status(9)", Status(9).String())
+
+	s, err := ParseStatus("disabled")
+	assert.Nil(t, err)
+	assert.Equal(t, StatusDisabled, s)
+	s, err = ParseStatus("3")
+	assert.Nil(t, err)
+	assert.Equal(t, StatusInReview, s)
+	_, err = ParseStatus("257")
+	assert.True(t, errors.Is(err, ErrInvalidStatus))
+
+	var v struct {
+		A Status `json:"a"`
+		B Status `json:"b"`
+		C Status `json:"c"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"a":"active","b":2,"c":null}`), &v))
+	b, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"a":"active","b":"disabled","c":null}`, string(b))
+	assert.NotNil(t, json.Unmarshal([]byte(`{"a":"deleted"}`), &v))
+	_, err = json.Marshal(Status(9))
+	assert.NotNil(t, err)
+
+	value, err := StatusActive.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), value)
+	value, err = Status(0).Value()
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	assert.Nil(t, s.Scan(int64(2)))
+	assert.Equal(t, StatusDisabled, s)
+	assert.Nil(t, s.Scan([]byte("3")))
+	assert.Equal(t, StatusInReview, s)
+	assert.NotNil(t, s.Scan(int64(4)))
+}
+
+func TestColor(t *testing.T) {
+	assert.Equal(t, Color("light_blue"), ColorLightBlue)
+	assert.Equal(t, "浅蓝", ColorLightBlue.CN())
+
+	c, err := ParseColor("red")
+	assert.Nil(t, err)
+	assert.Equal(t, ColorRed, c)
+	assert.NotNil(t, c.Scan("green"))
+
+	value, err := ColorLightBlue.Value()
+	assert.Nil(t, err)
+	assert.Equal(t, "light_blue", value)
+}
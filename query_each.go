@@ -0,0 +1,128 @@
+package gotools
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStopEach 由 Each 的回调返回时停止遍历，Each 返回 nil。
+var ErrStopEach = errors.New("stop each")
+
+// EachRow 是 Each 遍历到的一行数据。
+type EachRow struct {
+	// ResultSet 为结果集的序号，从 0 开始
+	ResultSet int
+	// Index 为行在当前结果集中的序号，从 0 开始
+	Index   int
+	Columns []string
+	Values  []interface{}
+}
+
+// Map 返回列名对应的值。
+func (r EachRow) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(r.Columns))
+	for idx, column := range r.Columns {
+		m[column] = r.Values[idx]
+	}
+	return m
+}
+
+// Each 执行 SQL 语句，逐行回调 f 而不将所有行保存在内存中，适用于大量数据的导出。
+// f 返回 ErrStopEach 时停止遍历并返回 nil，返回其他错误时停止遍历并返回该错误。
+// 语句返回多个结果集时（如存储过程）依次遍历各结果集。
+// Usage:
+//
+//	err := Each(ctx, dbPtr, "select id, name from users", nil, func(row EachRow) error {
+//		return w.Write(row.Values)
+//	})
+func Each(ctx context.Context, db DB, sql string, args []interface{}, f func(row EachRow) error) error {
+	rows, err := db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return EachRows(rows, f)
+}
+
+// EachRows 同 Each，遍历已有的 rows，rows 由调用方关闭。
+func EachRows(rows Rows, f func(row EachRow) error) error {
+	for resultSet := 0; ; resultSet++ {
+		columns, err := rows.Columns()
+		if err != nil {
+			return err
+		}
+
+		columnPointers := make([]interface{}, len(columns))
+		for idx := 0; rows.Next(); idx++ {
+			// This is necessary because the sql package requires pointers when scanning
+			for i := range columnPointers {
+				columnPointers[i] = new(interface{})
+			}
+			if err = rows.Scan(columnPointers...); err != nil {
+				return err
+			}
+
+			values := make([]interface{}, len(columns))
+			for i := range columns {
+				values[i] = *columnPointers[i].(*interface{})
+			}
+			if err = f(EachRow{ResultSet: resultSet, Index: idx, Columns: columns, Values: values}); err != nil {
+				return stopEach(err)
+			}
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		if !rows.NextResultSet() {
+			return rows.Err()
+		}
+	}
+}
+
+// EachStruct 同 Each，按 QueryStructs 的规则将各行映射为 T 后回调 f，
+// 多个结果集时各结果集均映射为 T。
+func EachStruct[T any](ctx context.Context, db DB, sql string, args []interface{}, f func(x T) error) error {
+	rows, err := db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return EachStructRows(rows, f)
+}
+
+// EachStructRows 同 EachStruct，遍历已有的 rows，rows 由调用方关闭。
+func EachStructRows[T any](rows Rows, f func(x T) error) error {
+	for {
+		scan, err := newRowScanner[T](rows)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			x, err := scan()
+			if err != nil {
+				return err
+			}
+			if err = f(x); err != nil {
+				return stopEach(err)
+			}
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		if !rows.NextResultSet() {
+			return rows.Err()
+		}
+	}
+}
+
+func stopEach(err error) error {
+	if errors.Is(err, ErrStopEach) {
+		return nil
+	}
+	return err
+}
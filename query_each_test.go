@@ -0,0 +1,59 @@
+package gotools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newEachRows() *fakeRows {
+	return &fakeRows{
+		columns: []string{"id", "user_name"},
+		rows:    [][]interface{}{{int64(1), "a"}, {int64(2), "b"}},
+		more: []*fakeRows{{
+			columns: []string{"id"},
+			rows:    [][]interface{}{{int64(3)}},
+		}},
+	}
+}
+
+func TestEachRows(t *testing.T) {
+	var got []EachRow
+	err := EachRows(newEachRows(), func(row EachRow) error {
+		got = append(got, row)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Len(t, got, 3)
+	assert.Equal(t, map[string]interface{}{"id": int64(2), "user_name": "b"}, got[1].Map())
+	assert.Equal(t, 1, got[2].ResultSet)
+	assert.Equal(t, 0, got[2].Index)
+
+	n := 0
+	err = EachRows(newEachRows(), func(row EachRow) error {
+		n++
+		return ErrStopEach
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, n)
+
+	errBoom := errors.New("boom")
+	err = EachRows(newEachRows(), func(row EachRow) error {
+		return errBoom
+	})
+	assert.ErrorIs(t, err, errBoom)
+}
+
+func TestEachStructRows(t *testing.T) {
+	var ids []int64
+	var names []string
+	err := EachStructRows(newEachRows(), func(u scanUser) error {
+		ids = append(ids, u.ID)
+		names = append(names, u.Name)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+	assert.Equal(t, []string{"a", "b", ""}, names)
+}
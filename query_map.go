@@ -1,5 +1,7 @@
 package gotools
 
+import "context"
+
 // Query 用于快速执行 SQL 语句，返回 []string（Columns 列名）、[][]interface{}（各行对应的值），对 value 操作时，需要知道其字段对应的类型。
 // Usage:
 // _, a, _ := Query(dbPtr, "select first_name from customers where balance >= ?", 1000)
@@ -8,7 +10,12 @@ package gotools
 //		fmt.Println(a[i].(string)) // you must know what type the db driver converts your columns to
 //	}
 func Query(db DB, sql string, args ...interface{}) ([]string, [][]interface{}, error) {
-	rows, err := db.Query(sql, args...)
+	return QueryContext(context.Background(), db, sql, args...)
+}
+
+// QueryContext 同 Query，ctx 用于取消查询。
+func QueryContext(ctx context.Context, db DB, sql string, args ...interface{}) ([]string, [][]interface{}, error) {
+	rows, err := db.QueryContext(ctx, sql, args...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -55,7 +62,12 @@ func Query(db DB, sql string, args ...interface{}) ([]string, [][]interface{}, e
 //		fmt.Println(a["first_name"].(string)) // you must know what type the db driver converts your columns to
 //	}
 func QueryMap(db DB, sql string, args ...interface{}) ([]string, []map[string]interface{}, error) {
-	rows, err := db.Query(sql, args...)
+	return QueryMapContext(context.Background(), db, sql, args...)
+}
+
+// QueryMapContext 同 QueryMap，ctx 用于取消查询。
+func QueryMapContext(ctx context.Context, db DB, sql string, args ...interface{}) ([]string, []map[string]interface{}, error) {
+	rows, err := db.QueryContext(ctx, sql, args...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -98,7 +110,12 @@ func QueryMap(db DB, sql string, args ...interface{}) ([]string, []map[string]in
 
 // QueryMapOne 是 QueryMap 的单个结果。
 func QueryMapOne(db DB, sql string, args ...interface{}) ([]string, map[string]interface{}, error) {
-	columns, xs, err := QueryMap(db, sql, args...)
+	return QueryMapOneContext(context.Background(), db, sql, args...)
+}
+
+// QueryMapOneContext 同 QueryMapOne，ctx 用于取消查询。
+func QueryMapOneContext(ctx context.Context, db DB, sql string, args ...interface{}) ([]string, map[string]interface{}, error) {
+	columns, xs, err := QueryMapContext(ctx, db, sql, args...)
 	if len(xs) == 0 {
 		return columns, nil, err
 	}
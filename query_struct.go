@@ -0,0 +1,252 @@
+package gotools
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// 列名对应字段的标签，按顺序查找，均未设置时为字段名的蛇形命名
+var structColumnTagNames = []string{"gorm", "db", "json"}
+
+// QueryStructs 执行 SQL 语句，按列名将各行映射为 T 的字段，T 为结构体或结构体指针。
+// 列名通过 gorm、db、json 标签获取（参考 StructFieldName），未设置标签时为字段名的蛇形命名；
+// 支持嵌套结构体及实现 sql.Scanner 的字段（如 datatypes.Time、DBJsonField），
+// 没有对应字段的列会被忽略，NULL 值对应非指针字段时为零值。
+// Usage:
+//
+//	users, err := QueryStructs[User](dbPtr, "select id, name from users where age >= ?", 18)
+func QueryStructs[T any](db DB, sql string, args ...interface{}) ([]T, error) {
+	return QueryStructsContext[T](context.Background(), db, sql, args...)
+}
+
+// QueryStructsContext 同 QueryStructs，ctx 用于取消查询。
+func QueryStructsContext[T any](ctx context.Context, db DB, sql string, args ...interface{}) ([]T, error) {
+	rows, err := db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return ScanStructs[T](rows)
+}
+
+// QueryOne 是 QueryStructs 的单个结果，没有结果时返回 nil。
+func QueryOne[T any](db DB, sql string, args ...interface{}) (*T, error) {
+	return QueryOneContext[T](context.Background(), db, sql, args...)
+}
+
+// QueryOneContext 同 QueryOne，ctx 用于取消查询。
+func QueryOneContext[T any](ctx context.Context, db DB, sql string, args ...interface{}) (*T, error) {
+	rows, err := db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	xs, err := scanStructs[T](rows, 1)
+	if len(xs) == 0 {
+		return nil, err
+	}
+	return &xs[0], err
+}
+
+// ScanStructs 将 rows 的所有行映射为 T，映射规则同 QueryStructs，rows 由调用方关闭。
+func ScanStructs[T any](rows Rows) ([]T, error) {
+	return scanStructs[T](rows, -1)
+}
+
+// limit < 0 时不限制行数
+func scanStructs[T any](rows Rows, limit int) ([]T, error) {
+	scan, err := newRowScanner[T](rows)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]T, 0)
+	for (limit < 0 || len(res) < limit) && rows.Next() {
+		x, err := scan()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, x)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// newRowScanner 返回将 rows 当前行映射为 T 的函数，rows 切换结果集后需重新获取
+func newRowScanner[T any](rows Rows) (func() (T, error), error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	structType, isPtr := typ, false
+	if typ.Kind() == reflect.Ptr {
+		structType, isPtr = typ.Elem(), true
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", typ)
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	indexes := getStructPlan(structType).columnIndexes(columns)
+
+	return func() (T, error) {
+		var x T
+		rv := reflect.New(structType)
+		scanner := newStructScanner(rv.Elem(), indexes)
+		if err := rows.Scan(scanner.dest...); err != nil {
+			return x, err
+		}
+		scanner.assign()
+
+		if isPtr {
+			x = rv.Interface().(T)
+		} else {
+			x = rv.Elem().Interface().(T)
+		}
+		return x, nil
+	}, nil
+}
+
+// structPlan 缓存结构体列名对应的字段索引
+type structPlan struct {
+	fields map[string][]int
+}
+
+var structPlans sync.Map // map[reflect.Type]*structPlan
+
+func getStructPlan(t reflect.Type) *structPlan {
+	if plan, ok := structPlans.Load(t); ok {
+		return plan.(*structPlan)
+	}
+
+	plan := &structPlan{fields: map[string][]int{}}
+	depths := map[string]int{}
+	plan.parse(t, nil, depths)
+	actual, _ := structPlans.LoadOrStore(t, plan)
+	return actual.(*structPlan)
+}
+
+// parse 解析结构体字段，嵌套结构体与外层字段同名时，外层字段优先
+func (p *structPlan) parse(t reflect.Type, index []int, depths map[string]int) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && ft.Kind() == reflect.Struct && !reflect.PtrTo(ft).Implements(scannerType) {
+			// 不可导出的嵌套结构体指针无法初始化
+			if sf.IsExported() || sf.Type.Kind() != reflect.Ptr {
+				p.parse(ft, fieldIndex, depths)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := structColumnName(sf)
+		if name == "" {
+			continue
+		}
+		if depth, ok := depths[name]; ok && depth <= len(index) {
+			continue
+		}
+		depths[name] = len(index)
+		p.fields[name] = fieldIndex
+	}
+}
+
+// structColumnName 返回字段对应的列名，忽略的字段返回空字符串
+func structColumnName(sf reflect.StructField) string {
+	name := StructFieldName(sf, structColumnTagNames...)
+	if i := strings.IndexByte(name, ','); i >= 0 {
+		name = name[:i]
+	}
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// columnIndexes 返回各列对应的字段索引，没有对应字段时为 nil
+func (p *structPlan) columnIndexes(columns []string) [][]int {
+	indexes := make([][]int, len(columns))
+	for i, column := range columns {
+		index, ok := p.fields[column]
+		if !ok {
+			index = p.fields[strings.ToLower(column)]
+		}
+		indexes[i] = index
+	}
+	return indexes
+}
+
+// structScanner 为一行数据准备 Scan 的参数
+type structScanner struct {
+	dest []interface{}
+	// 非指针、非 sql.Scanner 字段先扫描到指针中，以支持 NULL 值
+	nullable []nullableField
+}
+
+type nullableField struct {
+	field reflect.Value
+	ptr   reflect.Value
+}
+
+func newStructScanner(rv reflect.Value, indexes [][]int) *structScanner {
+	s := &structScanner{dest: make([]interface{}, len(indexes))}
+	for i, index := range indexes {
+		if index == nil {
+			s.dest[i] = new(interface{})
+			continue
+		}
+
+		field := fieldByIndex(rv, index)
+		switch {
+		case field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface,
+			field.Addr().Type().Implements(scannerType):
+			s.dest[i] = field.Addr().Interface()
+		default:
+			ptr := reflect.New(reflect.PtrTo(field.Type()))
+			s.nullable = append(s.nullable, nullableField{field: field, ptr: ptr})
+			s.dest[i] = ptr.Interface()
+		}
+	}
+	return s
+}
+
+func (s *structScanner) assign() {
+	for _, f := range s.nullable {
+		if p := f.ptr.Elem(); !p.IsNil() {
+			f.field.Set(p.Elem())
+		}
+	}
+}
+
+// fieldByIndex 同 reflect.Value.FieldByIndex，nil 的嵌套结构体指针会被初始化
+func fieldByIndex(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
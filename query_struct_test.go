@@ -0,0 +1,111 @@
+package gotools
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/joker-circus/gotools/datatypes"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRows 按顺序返回给定的行，more 为后续的结果集
+type fakeRows struct {
+	columns []string
+	rows    [][]interface{}
+	cur     int
+	more    []*fakeRows
+}
+
+func (r *fakeRows) Close() error                            { return nil }
+func (r *fakeRows) Columns() ([]string, error)              { return r.columns, nil }
+func (r *fakeRows) ColumnTypes() ([]*sql.ColumnType, error) { return nil, nil }
+func (r *fakeRows) Err() error                              { return nil }
+
+func (r *fakeRows) NextResultSet() bool {
+	if len(r.more) == 0 {
+		return false
+	}
+	next := r.more[0]
+	r.columns, r.rows, r.cur, r.more = next.columns, next.rows, 0, r.more[1:]
+	return true
+}
+
+func (r *fakeRows) Next() bool {
+	r.cur++
+	return r.cur <= len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	row := r.rows[r.cur-1]
+	for i, d := range dest {
+		switch d := d.(type) {
+		case sql.Scanner:
+			if err := d.Scan(row[i]); err != nil {
+				return err
+			}
+		case *interface{}:
+			*d = row[i]
+		case **string:
+			if row[i] != nil {
+				s := row[i].(string)
+				*d = &s
+			}
+		case **int64:
+			if row[i] != nil {
+				n := row[i].(int64)
+				*d = &n
+			}
+		default:
+			return errors.New("unsupported dest")
+		}
+	}
+	return nil
+}
+
+type scanBase struct {
+	ID        int64          `gorm:"column:id"`
+	CreatedAt datatypes.Time `json:"created_at"`
+}
+
+type scanUser struct {
+	scanBase
+	Name    string                                `db:"user_name"`
+	Tags    datatypes.DBStringSlice               `json:"tags,omitempty"`
+	Profile datatypes.DBJsonField[map[string]int] `json:"profile"`
+	Nick    *string
+	Ignored string `json:"-"`
+}
+
+func TestScanStructs(t *testing.T) {
+	now := time.Now()
+	rows := &fakeRows{
+		columns: []string{"id", "user_name", "tags", "profile", "nick", "created_at", "unknown"},
+		rows: [][]interface{}{
+			{int64(1), "joker", []byte(`"a,b"`), []byte(`{"age":18}`), "j", now, "x"},
+			{int64(2), nil, nil, nil, nil, nil, nil},
+		},
+	}
+
+	users, err := ScanStructs[*scanUser](rows)
+	assert.Nil(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, int64(1), users[0].ID)
+	assert.Equal(t, "joker", users[0].Name)
+	assert.Equal(t, datatypes.DBStringSlice{"a", "b"}, users[0].Tags)
+	assert.Equal(t, 18, users[0].Profile.Data()["age"])
+	assert.Equal(t, "j", *users[0].Nick)
+	assert.True(t, now.Equal(time.Time(users[0].CreatedAt)))
+	assert.Equal(t, "", users[1].Name)
+	assert.Nil(t, users[1].Nick)
+
+	plan := getStructPlan(reflect.TypeOf(scanUser{}))
+	_, ok := plan.fields["ignored"]
+	assert.False(t, ok)
+	assert.Equal(t, []int{0, 0}, plan.fields["id"])
+
+	_, err = ScanStructs[int](&fakeRows{})
+	assert.NotNil(t, err)
+}
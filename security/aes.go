@@ -6,6 +6,7 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"errors"
 	"io"
 )
 
@@ -135,3 +136,37 @@ func AesDecryptCFB(encrypted []byte, key []byte) (decrypted []byte) {
 	stream.XORKeyStream(encrypted, encrypted)
 	return encrypted
 }
+
+// AES加密，GCM，随机 nonce 置于密文之前，additionalData 为附加认证数据，可为 nil
+func AesEncryptGCM(origData, key, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(origData)+gcm.Overhead())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, origData, additionalData), nil
+}
+
+// AES解密，GCM，密文或 additionalData 被篡改时返回错误
+func AesDecryptGCM(encrypted, key, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(encrypted) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, encrypted := encrypted[:gcm.NonceSize()], encrypted[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, encrypted, additionalData)
+}
@@ -0,0 +1,288 @@
+package gotools
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrTxManaged 在 WithTx 的回调中调用了 Commit 或 Rollback，事务由 WithTx 提交或回滚
+	ErrTxManaged = errors.New("transaction is managed by WithTx")
+	// ErrTxDB WithTx 的 db 既不是 DB，也不是 WithTx 回调中的 Tx
+	ErrTxDB = errors.New("WithTx requires a DB or a Tx passed by WithTx")
+)
+
+// TxPanicError 为事务回调中 panic 转换的错误
+type TxPanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *TxPanicError) Error() string {
+	return fmt.Sprintf("panic in transaction: %v\n%s", e.Value, e.Stack)
+}
+
+// TxOptions 为 WithTx 的选项，nil 时使用默认值。
+type TxOptions struct {
+	// Options 为开启事务的选项，如隔离级别
+	Options *sql.TxOptions
+	// MaxRetries 事务因序列化失败、死锁等错误失败时的最大重试次数，0 为不重试。
+	// 只有最外层事务会重试，重试时回调会被再次执行。
+	MaxRetries int
+	// Backoff 重试的等待时间，第 n 次重试等待 n*Backoff，默认 10ms
+	Backoff time.Duration
+	// IsRetryable 判断错误是否可以重试，默认为 IsRetryableTxError
+	IsRetryable func(error) bool
+}
+
+// managedTx 为传给 WithTx 回调的 Tx，嵌套调用时为 SAVEPOINT
+type managedTx struct {
+	Tx
+	depth int
+	seq   *int
+	hooks []func()
+}
+
+func (t *managedTx) Commit() error {
+	return ErrTxManaged
+}
+
+func (t *managedTx) Rollback() error {
+	return ErrTxManaged
+}
+
+// WithTx 在事务中执行 fn，fn 返回 nil 时提交事务，返回错误或 panic 时回滚事务，
+// panic 转换为 *TxPanicError 返回。
+// db 为 fn 的 tx 时为嵌套事务，使用 SAVEPOINT 实现，嵌套事务失败只回滚到 SAVEPOINT，
+// 嵌套事务的 opts 被忽略。
+// 事务提交后依次执行 AfterCommit 注册的回调，如清理缓存。
+// Usage:
+//
+//	err := WithTx(ctx, dbPtr, &TxOptions{MaxRetries: 3}, func(tx Tx) error {
+//		if _, err := tx.ExecContext(ctx, "update accounts set balance = balance - ? where id = ?", 100, 1); err != nil {
+//			return err
+//		}
+//		AfterCommit(tx, func() {
+//			cache.DeleteIf(func(key string) bool { return strings.HasPrefix(key, "account:") })
+//		})
+//		return WithTx(ctx, tx, nil, func(tx Tx) error {
+//			_, err := tx.ExecContext(ctx, "insert into logs (account_id) values (?)", 1)
+//			return err
+//		})
+//	})
+func WithTx(ctx context.Context, db Stmt, opts *TxOptions, fn func(tx Tx) error) error {
+	switch d := db.(type) {
+	case *managedTx:
+		return withSavepoint(ctx, d, fn)
+	case DB:
+		return withRetry(ctx, d, opts, fn)
+	default:
+		return ErrTxDB
+	}
+}
+
+// AfterCommit 注册最外层事务提交后执行的回调，嵌套事务回滚时其中注册的回调被丢弃。
+// tx 不是 WithTx 回调中的 Tx 时返回 false。
+func AfterCommit(tx Tx, f func()) bool {
+	t, ok := tx.(*managedTx)
+	if !ok {
+		return false
+	}
+	t.hooks = append(t.hooks, f)
+	return true
+}
+
+func withRetry(ctx context.Context, db DB, opts *TxOptions, fn func(tx Tx) error) error {
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = 10 * time.Millisecond
+	}
+	isRetryable := opts.IsRetryable
+	if isRetryable == nil {
+		isRetryable = IsRetryableTxError
+	}
+
+	for attempt := 0; ; attempt++ {
+		hooks, err := runTx(ctx, db, opts.Options, fn)
+		if err == nil {
+			for _, hook := range hooks {
+				hook()
+			}
+			return nil
+		}
+
+		if attempt >= opts.MaxRetries || !isRetryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(time.Duration(attempt+1) * backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// runTx 执行一次事务，成功时返回需要执行的回调
+func runTx(ctx context.Context, db DB, opts *sql.TxOptions, fn func(tx Tx) error) ([]func(), error) {
+	sqlTx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &managedTx{Tx: sqlTx, seq: new(int)}
+	if err = callTx(tx, fn); err != nil {
+		if rbErr := sqlTx.Rollback(); rbErr != nil {
+			err = errors.Join(err, rbErr)
+		}
+		return nil, err
+	}
+
+	if err = sqlTx.Commit(); err != nil {
+		return nil, err
+	}
+	return tx.hooks, nil
+}
+
+func withSavepoint(ctx context.Context, parent *managedTx, fn func(tx Tx) error) error {
+	*parent.seq++
+	name := "sp_" + strconv.Itoa(*parent.seq)
+	if _, err := parent.Tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	tx := &managedTx{Tx: parent.Tx, depth: parent.depth + 1, seq: parent.seq}
+	if err := callTx(tx, fn); err != nil {
+		if _, rbErr := parent.Tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			err = errors.Join(err, rbErr)
+		}
+		return err
+	}
+
+	if _, err := parent.Tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return err
+	}
+	parent.hooks = append(parent.hooks, tx.hooks...)
+	return nil
+}
+
+// callTx 执行回调，panic 转换为 *TxPanicError
+func callTx(tx *managedTx, fn func(tx Tx) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &TxPanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(tx)
+}
+
+// IsRetryableTxError 判断是否为可重试的事务错误：
+// MySQL 死锁（1213）、锁等待超时（1205），PostgreSQL 序列化失败（40001）、死锁（40P01），
+// SQLite 数据库被锁（SQLITE_BUSY、SQLITE_LOCKED）。不依赖具体的驱动，按错误码字段及最内层错误的信息判断，
+// errors.Join 合并的错误（如回滚失败时）逐个判断，SQLite 的错误码只在 SQLite 驱动的错误类型上判断。回调 panic 的 *TxPanicError 不会重试。
+func IsRetryableTxError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var panicErr *TxPanicError
+	if errors.As(err, &panicErr) {
+		return false
+	}
+
+	return isRetryableTxError(err)
+}
+
+// isRetryableTxError 逐层展开 err，包括 errors.Join 合并的多个错误，任一错误可重试即返回 true
+func isRetryableTxError(err error) bool {
+	if code, ok := txErrorCode(err); ok {
+		switch code {
+		case "1213", "1205", "40001", "40P01":
+			return true
+		case "5", "6":
+			if isSQLiteError(err) {
+				return true
+			}
+		}
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			return isRetryableTxError(inner)
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if inner != nil && isRetryableTxError(inner) {
+				return true
+			}
+		}
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{
+		"deadlock",
+		"lock wait timeout",
+		"could not serialize access",
+		"database is locked",
+		"database table is locked",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSQLiteError 判断是否为 SQLite 驱动的错误，如 github.com/mattn/go-sqlite3 及 modernc.org/sqlite
+func isSQLiteError(err error) bool {
+	t := reflect.TypeOf(err)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return isSQLitePkg(t.PkgPath())
+}
+
+func isSQLitePkg(path string) bool {
+	return strings.Contains(strings.ToLower(path[strings.LastIndexByte(path, '/')+1:]), "sqlite")
+}
+
+// txErrorCode 获取驱动错误的错误码，如 mysql.MySQLError.Number、pq.Error.Code、
+// pgconn.PgError.Code 及 sqlite3.Error.Code
+func txErrorCode(err error) (string, bool) {
+	if e, ok := err.(interface{ SQLState() string }); ok {
+		return e.SQLState(), true
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(err))
+	if rv.Kind() != reflect.Struct {
+		return "", false
+	}
+	for _, name := range []string{"Number", "Code"} {
+		f := rv.FieldByName(name)
+		if !f.IsValid() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.String:
+			return f.String(), true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(f.Int(), 10), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return strconv.FormatUint(f.Uint(), 10), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,164 @@
+package gotools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/joker-circus/gotools/dbutil/dbmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTxSavepoint(t *testing.T) {
+	db, mock := dbmock.New()
+	defer mock.Close()
+	defer db.Close()
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("update accounts").WillReturnResult(dbmock.NewResult(0, 1))
+	mock.ExpectExec("SAVEPOINT sp_1")
+	mock.ExpectExec("insert into logs").WillReturnError(errors.New("boom"))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1")
+	mock.ExpectExec("SAVEPOINT sp_2")
+	mock.ExpectExec("RELEASE SAVEPOINT sp_2")
+	mock.ExpectCommit()
+
+	var hooks []string
+	err := WithTx(ctx, db, nil, func(tx Tx) error {
+		if _, err := tx.ExecContext(ctx, "update accounts set balance = 0"); err != nil {
+			return err
+		}
+		assert.ErrorIs(t, tx.Commit(), ErrTxManaged)
+		assert.True(t, AfterCommit(tx, func() { hooks = append(hooks, "outer") }))
+
+		err := WithTx(ctx, tx, nil, func(tx Tx) error {
+			AfterCommit(tx, func() { hooks = append(hooks, "dropped") })
+			_, err := tx.ExecContext(ctx, "insert into logs values (1)")
+			return err
+		})
+		assert.EqualError(t, err, "boom")
+
+		return WithTx(ctx, tx, nil, func(tx Tx) error {
+			AfterCommit(tx, func() { hooks = append(hooks, "inner") })
+			return nil
+		})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"outer", "inner"}, hooks)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTxPanic(t *testing.T) {
+	db, mock := dbmock.New()
+	defer mock.Close()
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err := WithTx(context.Background(), db, nil, func(tx Tx) error {
+		AfterCommit(tx, func() { t.Fatal("hook should not run") })
+		panic("oops")
+	})
+	var panicErr *TxPanicError
+	assert.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, "oops", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	assert.ErrorIs(t, WithTx(context.Background(), nil, nil, nil), ErrTxDB)
+}
+
+type testDriverError struct {
+	Number uint16
+}
+
+func (e *testDriverError) Error() string {
+	return "driver error"
+}
+
+func TestWithTxRetry(t *testing.T) {
+	db, mock := dbmock.New()
+	defer mock.Close()
+	defer db.Close()
+
+	deadlock := &testDriverError{Number: 1213}
+	mock.ExpectBegin()
+	mock.ExpectExec("update").WillReturnError(deadlock)
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("update")
+	mock.ExpectCommit().WillReturnError(errors.New("ERROR: could not serialize access due to concurrent update"))
+	mock.ExpectBegin()
+	mock.ExpectExec("update")
+	mock.ExpectCommit()
+
+	attempts := 0
+	err := WithTx(context.Background(), db, &TxOptions{MaxRetries: 3, Backoff: time.Millisecond}, func(tx Tx) error {
+		attempts++
+		_, err := tx.Exec("update accounts set balance = 0")
+		return err
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Nil(t, mock.ExpectationsWereMet())
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	err = WithTx(context.Background(), db, &TxOptions{MaxRetries: 3, IsRetryable: func(error) bool { return false }}, func(tx Tx) error {
+		return deadlock
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	assert.False(t, IsRetryableTxError(nil))
+	assert.True(t, IsRetryableTxError(&testDriverError{Number: 1205}))
+	assert.False(t, IsRetryableTxError(&testDriverError{Number: 1062}))
+	assert.True(t, IsRetryableTxError(errors.New("pq: deadlock detected")))
+	assert.True(t, IsRetryableTxError(errors.New("database is locked")))
+	assert.False(t, IsRetryableTxError(errors.New("duplicate entry")))
+
+	// SQLite 的错误码只在 SQLite 驱动的错误类型上判断
+	assert.False(t, IsRetryableTxError(&testDriverError{Number: 5}))
+	assert.False(t, IsRetryableTxError(fmt.Errorf("app: %w", &testDriverError{Number: 6})))
+	assert.True(t, isSQLitePkg("github.com/mattn/go-sqlite3"))
+	assert.True(t, isSQLitePkg("modernc.org/sqlite"))
+	assert.False(t, isSQLitePkg("github.com/joker-circus/gotools"))
+
+	// 只匹配最内层错误的信息，panic 不重试
+	assert.True(t, IsRetryableTxError(fmt.Errorf("commit: %w", errors.New("Deadlock found when trying to get lock"))))
+	assert.False(t, IsRetryableTxError(fmt.Errorf("database is locked: %w", errors.New("duplicate entry"))))
+	assert.False(t, IsRetryableTxError(&TxPanicError{Value: "oops", Stack: []byte("main.retryOnDeadlock()")}))
+	assert.False(t, IsRetryableTxError(fmt.Errorf("tx: %w", &TxPanicError{Value: "database is locked"})))
+
+	// 回滚失败时与回滚错误合并
+	rbErr := errors.New("rollback: bad connection")
+	assert.True(t, IsRetryableTxError(errors.Join(&testDriverError{Number: 1213}, rbErr)))
+	assert.True(t, IsRetryableTxError(fmt.Errorf("tx: %w", errors.Join(errors.New("pq: could not serialize access"), rbErr))))
+	assert.False(t, IsRetryableTxError(errors.Join(errors.New("duplicate entry"), rbErr)))
+	assert.False(t, IsRetryableTxError(errors.Join(&TxPanicError{Value: "oops"}, &testDriverError{Number: 1213})))
+}
+
+func TestWithTxPanicNotRetried(t *testing.T) {
+	db, mock := dbmock.New()
+	defer mock.Close()
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	attempts := 0
+	err := WithTx(context.Background(), db, &TxOptions{MaxRetries: 3, Backoff: time.Millisecond}, func(tx Tx) error {
+		attempts++
+		panic("deadlock detected")
+	})
+	var panicErr *TxPanicError
+	assert.ErrorAs(t, err, &panicErr)
+	assert.Equal(t, 1, attempts)
+	assert.Nil(t, mock.ExpectationsWereMet())
+}